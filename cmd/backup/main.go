@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/backup"
+	"github.com/fhmptrdnd/private-blog/internal/config"
+)
+
+// command, satu subcommand: nerima path database sama argumen sisanya
+type command func(dbPath string, args []string) error
+
+var commands = map[string]command{
+	"create":  createCmd,
+	"verify":  verifyCmd,
+	"restore": restoreCmd,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: backup [-db path] <command> [flags]
+
+commands:
+  create  [-dir backups] [-gzip] [-keep N]   snapshot database (aman pas server jalan)
+  verify  <file>                             cek integrity file backup
+  restore [-force] <file>                    timpa database dengan backup (server harus mati)`)
+}
+
+func main() {
+	// path database dari config yang sama kayak cmd/web, tapi flag server ga ikut
+	cfg, err := config.LoadDB("backup", os.Args[1:], os.Getenv)
+	if err != nil {
+		// -h udah nampilin usage sendiri, error lain (env/file config salah) harus keliatan
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		config.Print(os.Stdout, cfg)
+		return
+	}
+	if len(cfg.Args) == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[cfg.Args[0]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd(cfg.DBPath, cfg.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// createcmd, bikin snapshot + rotasi
+func createCmd(dbPath string, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	dir := fs.String("dir", "backups", "folder tujuan backup")
+	compress := fs.Bool("gzip", false, "kompres hasil backup pake gzip")
+	keep := fs.Int("keep", 0, "simpan n backup terbaru (0 = simpan semua)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	path, err := backup.Snapshot(dbPath, backup.Options{Dir: *dir, Compress: *compress, Keep: *keep}, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println("backup created:", path)
+	return nil
+}
+
+// verifycmd, cek integrity satu file backup
+func verifyCmd(_ string, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("verify butuh satu file")
+	}
+	if err := backup.Verify(args[0]); err != nil {
+		return err
+	}
+	fmt.Println("ok:", args[0])
+	return nil
+}
+
+// restorecmd, timpa database dengan file backup
+func restoreCmd(dbPath string, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ContinueOnError)
+	force := fs.Bool("force", false, "tetep restore walaupun ada file -wal sisa (bukan kalo server masih jalan)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("restore butuh satu file backup")
+	}
+
+	previous, err := backup.Restore(fs.Arg(0), dbPath, *force, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println("restored", dbPath, "from", fs.Arg(0))
+	if previous != "" {
+		fmt.Println("previous database kept at", previous)
+	}
+	return nil
+}
@@ -2,60 +2,398 @@ package main
 
 import (
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
-	_ "modernc.org/sqlite"
+	"github.com/fhmptrdnd/private-blog/internal/admin"
+	"github.com/fhmptrdnd/private-blog/internal/backup"
+	"github.com/fhmptrdnd/private-blog/internal/config"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+	"github.com/fhmptrdnd/private-blog/internal/service"
+	"github.com/fhmptrdnd/private-blog/internal/telegraph"
 )
 
+// command, satu subcommand admin
+type command struct {
+	usage string
+	noDB  bool // ga perlu buka database
+	run   func(db *sql.DB, args []string) error
+}
+
+var commands = map[string]command{
+	"list":               {"list [-owner id] [-author name] [-deleted exclude|include|only] [-since date] [-until date] [-format f]", false, listCmd},
+	"show":               {"show [-format f] <id>", false, showCmd},
+	"restore":            {"restore <id>", false, restoreCmd},
+	"purge":              {"purge [-older-than 720h] (-dry-run | -yes) [-format f]", false, purgeCmd},
+	"stats":              {"stats [-format f]", false, statsCmd},
+	"reassign-owner":     {"reassign-owner <from> <to>", false, reassignCmd},
+	"import":             {"import -owner id [-format f] <file.zip|folder|file.md>", true, importCmd},
+	"recompute-metadata": {"recompute-metadata", false, recomputeCmd},
+	"import-telegraph":   {"import-telegraph -owner id [-format f] <page.json>...", true, importTelegraphCmd},
+}
+
+// order, urutan subcommand di usage
+var order = []string{"list", "show", "restore", "purge", "stats", "reassign-owner", "recompute-metadata", "import", "import-telegraph"}
+
+// dbpath, path database dari config, buat subcommand yang buka repository sendiri
+var dbPath string
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: dbview [-db path] <command> [flags]\n\ncommands:")
+	for _, name := range order {
+		fmt.Fprintln(os.Stderr, "  "+commands[name].usage)
+	}
+	fmt.Fprintln(os.Stderr, "\nformat: table (default), json, csv; date: 2006-01-02 atau RFC3339")
+}
+
 func main() {
-	// Path ke database di cmd/web folder
-	db, err := sql.Open("sqlite", "../web/blog.db")
+	// path database diambil dari config yang sama kayak cmd/web
+	// (flag -db, env BLOG_DB_PATH, atau file -config)
+	cfg, err := config.LoadDB("dbview", os.Args[1:], os.Getenv)
+	if err != nil {
+		// -h udah nampilin usage sendiri, error lain (env/file config salah) harus keliatan
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		config.Print(os.Stdout, cfg)
+		return
+	}
+
+	dbPath = cfg.DBPath
+	args := cfg.Args
+	if len(args) == 0 {
+		// tanpa subcommand: kelakuan lama, tampilin semua artikel termasuk yang terhapus
+		args = []string{"list", "-deleted", "include"}
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+
+	var db *sql.DB
+	if !cmd.noDB {
+		db, err = admin.Open(cfg.DBPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		defer db.Close()
+	}
+
+	if err := cmd.run(db, args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
+
+// parsedate, terima tanggal doang atau rfc3339
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+func listCmd(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	owner := fs.String("owner", "", "filter owner id")
+	author := fs.String("author", "", "filter nama penulis")
+	deleted := fs.String("deleted", string(admin.DeletedExclude), "exclude, include, atau only")
+	since := fs.String("since", "", "dibuat sejak tanggal ini")
+	until := fs.String("until", "", "dibuat sebelum tanggal ini")
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	f := admin.Filter{Owner: *owner, Author: *author, Deleted: admin.DeletedFilter(*deleted)}
+	var err error
+	if f.Since, err = parseDate(*since); err != nil {
+		return fmt.Errorf("-since: %w", err)
+	}
+	if f.Until, err = parseDate(*until); err != nil {
+		return fmt.Errorf("-until: %w", err)
+	}
+
+	articles, err := admin.List(db, f)
+	if err != nil {
+		return err
+	}
+	return writeArticles(os.Stdout, *format, articles)
+}
+
+func showCmd(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("show butuh satu id")
+	}
+	a, err := admin.Show(db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeArticleDetail(os.Stdout, *format, a)
+}
+
+// serverstopped, tolak perubahan langsung ke database selama server jalan
+// server nyimpen artikel di cache (ttl semenit) yang ga tau ada perubahan dari luar,
+// jadi pembaca bisa dapet data lama atau views yang ketahan ketimpa
+func serverStopped() error {
+	if pid, running := backup.Running(dbPath); running {
+		return fmt.Errorf("server masih jalan (pid %d), matiin dulu biar cache-nya ga nyajiin data lama", pid)
+	}
+	return nil
+}
+
+func restoreCmd(db *sql.DB, args []string) error {
+	if len(args) != 1 {
+		return errors.New("restore butuh satu id")
+	}
+	if err := serverStopped(); err != nil {
+		return err
+	}
+	if err := admin.Restore(db, args[0]); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return fmt.Errorf("artikel %s ga ada atau ga lagi terhapus", args[0])
+		}
+		return err
+	}
+	fmt.Println("restored", args[0])
+	return nil
+}
+
+func purgeCmd(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ContinueOnError)
+	olderThan := fs.Duration("older-than", 720*time.Hour, "cuma yang dihapus lebih lama dari ini")
+	dryRun := fs.Bool("dry-run", false, "tampilin yang bakal dihapus tanpa beneran hapus")
+	yes := fs.Bool("yes", false, "beneran hapus permanen (wajib kalo bukan -dry-run)")
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !*dryRun {
+		// hapus permanen ga bisa dibatalin, jadi harus diminta jelas-jelas
+		if !*yes {
+			return errors.New("purge hapus permanen, jalanin dulu pake -dry-run terus ulangi pake -yes")
+		}
+		if err := serverStopped(); err != nil {
+			return err
+		}
+	}
+
+	victims, err := admin.Purge(db, time.Now().Add(-*olderThan), *dryRun)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer db.Close()
+	if err := writeArticles(os.Stdout, *format, victims); err != nil {
+		return err
+	}
+	verb := "purged"
+	if *dryRun {
+		verb = "would purge"
+	}
+	fmt.Fprintf(os.Stderr, "%s %d article(s)\n", verb, len(victims))
+	return nil
+}
+
+func statsCmd(db *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	s, err := admin.CollectStats(db)
+	if err != nil {
+		return err
+	}
+	return writeStats(os.Stdout, *format, s)
+}
+
+func reassignCmd(db *sql.DB, args []string) error {
+	if len(args) != 2 {
+		return errors.New("reassign-owner butuh <from> <to>")
+	}
+	if err := serverStopped(); err != nil {
+		return err
+	}
+	n, err := admin.ReassignOwner(db, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("moved %d article(s) from %s to %s\n", n, args[0], args[1])
+	return nil
+}
+
+// recomputecmd, hitung ulang jumlah kata, menit baca, sama cuplikan semua artikel
+// buat baris lama yang cuma dapet perkiraan dari migration
+func recomputeCmd(db *sql.DB, args []string) error {
+	if len(args) != 0 {
+		return errors.New("recompute-metadata ga nerima argumen")
+	}
+	if err := serverStopped(); err != nil {
+		return err
+	}
+	n, err := admin.RecomputeMetadata(db, service.WithMetadata())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("updated metadata of %d article(s)\n", n)
+	return nil
+}
+
+// importcmd, import file markdown lewat service, sama kayak form upload di web
+// lewat repository (bukan sql langsung) biar aturan id, tanggal, dan idempotensinya sama persis
+// repository dibuka tanpa migration dan ditolak selama server jalan, sama kayak perintah tulis lain
+func importCmd(_ *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	owner := fs.String("owner", "", "owner id yang bakal punya artikel hasil import (wajib)")
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *owner == "" || fs.NArg() != 1 {
+		return errors.New("import butuh -owner dan satu path zip, folder, atau file .md")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	if err := serverStopped(); err != nil {
+		return err
+	}
+
+	src, closeSrc, err := openImportSource(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer closeSrc()
 
-	// Query semua artikel
-	rows, err := db.Query(`
-		SELECT id, title, author, created_at, updated_at, views, owner_id, deleted_at
-		FROM articles
-		ORDER BY created_at DESC
-	`)
+	repo, err := repository.OpenSQLiteRepo(dbPath)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	defer rows.Close()
+	defer repo.Close()
+	svc := service.NewArticleService(repo, service.NewRealClock(), service.NewRealIDGen())
 
-	fmt.Println("Isi Database:\n")
-	count := 0
-	for rows.Next() {
-		var id, title, author, ownerID string
-		var createdAt, updatedAt string
-		var views int
-		var deletedAt sql.NullString
+	results, err := svc.Import(*owner, src)
+	if werr := writeImportResults(os.Stdout, *format, results); werr != nil && err == nil {
+		err = werr
+	}
+	if err != nil {
+		return err
+	}
+	return importSummary(results)
+}
 
-		err := rows.Scan(&id, &title, &author, &createdAt, &updatedAt, &views, &ownerID, &deletedAt)
+// importtelegraphcmd, import page telegraph (json dari getPage?return_content=true)
+func importTelegraphCmd(_ *sql.DB, args []string) error {
+	fs := flag.NewFlagSet("import-telegraph", flag.ContinueOnError)
+	owner := fs.String("owner", "", "owner id yang bakal punya artikel hasil import (wajib)")
+	format := formatFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *owner == "" || fs.NArg() == 0 {
+		return errors.New("import-telegraph butuh -owner dan minimal satu file json")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	if err := serverStopped(); err != nil {
+		return err
+	}
+
+	repo, err := repository.OpenSQLiteRepo(dbPath)
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	svc := service.NewArticleService(repo, service.NewRealClock(), service.NewRealIDGen())
+
+	// importpage, satu file json jadi satu hasil, gagal di satu file ga ngehentiin yang lain
+	importPage := func(name string) service.ImportResult {
+		res := service.ImportResult{File: name, Status: service.ImportFailed}
+		f, err := os.Open(name)
 		if err != nil {
-			log.Fatal(err)
+			res.Error = err.Error()
+			return res
+		}
+		defer f.Close()
+		page, err := telegraph.ParsePage(f)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		a, warnings := page.Article(*owner)
+		res.Title, res.Warnings = a.Title, warnings
+		if res.ID, res.Status, err = svc.ImportArticle(a); err != nil {
+			res.ID, res.Status, res.Error = a.ID, service.ImportFailed, err.Error()
 		}
+		return res
+	}
 
-		count++
-		fmt.Printf("--- Article #%d ---\n", count)
-		fmt.Printf("ID:         %s\n", id)
-		fmt.Printf("Title:      %s\n", title)
-		fmt.Printf("Author:     %s\n", author)
-		fmt.Printf("Created:    %s\n", createdAt)
-		fmt.Printf("Updated:    %s\n", updatedAt)
-		fmt.Printf("Views:      %d\n", views)
-		fmt.Printf("Owner:      %s\n", ownerID)
-		if deletedAt.Valid {
-			fmt.Printf("Deleted:    %s [SOFT DELETED]\n", deletedAt.String)
-		} else {
-			fmt.Printf("Deleted:    [ACTIVE]\n")
+	var results []service.ImportResult
+	for _, name := range fs.Args() {
+		results = append(results, importPage(name))
+	}
+	if err := writeImportResults(os.Stdout, *format, results); err != nil {
+		return err
+	}
+	return importSummary(results)
+}
+
+// importsummary, ringkasan hasil import ke stderr, error kalo ada file yang gagal
+func importSummary(results []service.ImportResult) error {
+	for _, r := range results {
+		for _, w := range r.Warnings {
+			fmt.Fprintf(os.Stderr, "warning: %s: %s\n", r.File, w)
 		}
-		fmt.Println()
 	}
+	counts := map[string]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	fmt.Fprintf(os.Stderr, "%d created, %d updated, %d unchanged, %d failed\n",
+		counts[service.ImportCreated], counts[service.ImportUpdated], counts[service.ImportUnchanged], counts[service.ImportFailed])
+	if counts[service.ImportFailed] > 0 {
+		return fmt.Errorf("%d file gagal di-import", counts[service.ImportFailed])
+	}
+	return nil
+}
 
-	fmt.Printf("Total: %d articles\n", count)
+// openimportsource, pilih sumber import dari path: folder, zip, atau satu file markdown
+func openImportSource(p string) (service.ImportSource, func() error, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.IsDir() {
+		return service.DirSource(p), func() error { return nil }, nil
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.EqualFold(filepath.Ext(p), ".zip") {
+		return service.ZipSource(f, info.Size()), f.Close, nil
+	}
+	return service.FileSource(filepath.Base(p), f), f.Close, nil
 }
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/admin"
+	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/service"
+)
+
+// formatflag, daftarin flag -format ke flagset subcommand
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", "table", "format output: table, json, csv")
+}
+
+// checkformat, tolak format yang ga dikenal
+func checkFormat(format string) error {
+	switch format {
+	case "table", "json", "csv":
+		return nil
+	}
+	return fmt.Errorf("format %q ga dikenal (table/json/csv)", format)
+}
+
+// status, teks status artikel
+func status(a models.Article) string {
+	if a.DeletedAt != nil {
+		return "deleted " + a.DeletedAt.Format(time.RFC3339)
+	}
+	return "active"
+}
+
+// plaincontent, ubah <br> balik jadi newline biar enak dibaca di terminal
+func plainContent(content string) string {
+	return strings.ReplaceAll(content, "<br>", "\n")
+}
+
+// writejson, tulis value apa aja sebagai json rapi
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// articlerow, kolom yang dipake di tabel dan csv
+var articleHeader = []string{"id", "title", "author", "created_at", "updated_at", "views", "owner_id", "status"}
+
+func articleRow(a models.Article) []string {
+	return []string{
+		a.ID, a.Title, a.Author,
+		a.CreatedAt.Format(time.RFC3339), a.UpdatedAt.Format(time.RFC3339),
+		strconv.Itoa(a.Views), a.OwnerID, status(a),
+	}
+}
+
+// writearticles, tulis daftar artikel sesuai format
+func writeArticles(w io.Writer, format string, articles []models.Article) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		if articles == nil {
+			articles = []models.Article{}
+		}
+		return writeJSON(w, articles)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(articleHeader)
+		for _, a := range articles {
+			cw.Write(articleRow(a))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(articleHeader, "\t")))
+	for _, a := range articles {
+		fmt.Fprintln(tw, strings.Join(articleRow(a), "\t"))
+	}
+	fmt.Fprintf(tw, "\nTotal: %d articles\n", len(articles))
+	return tw.Flush()
+}
+
+// writearticledetail, tulis satu artikel lengkap sama isinya
+func writeArticleDetail(w io.Writer, format string, a models.Article) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		return writeJSON(w, a)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(append(articleHeader, "content"))
+		cw.Write(append(articleRow(a), plainContent(a.Content)))
+		cw.Flush()
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	for i, col := range articleRow(a) {
+		fmt.Fprintf(tw, "%s:\t%s\n", articleHeader[i], col)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%s\n", plainContent(a.Content))
+	return nil
+}
+
+// writestats, tulis ringkasan statistik
+func writeStats(w io.Writer, format string, s admin.Stats) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	rows := [][]string{
+		{"active", strconv.Itoa(s.Active)},
+		{"deleted", strconv.Itoa(s.Deleted)},
+		{"owners", strconv.Itoa(s.Owners)},
+		{"authors", strconv.Itoa(s.Authors)},
+		{"total_views", strconv.Itoa(s.TotalViews)},
+		{"top_article", fmt.Sprintf("%s %q (%d views)", s.TopID, s.TopTitle, s.TopViews)},
+	}
+	switch format {
+	case "json":
+		return writeJSON(w, s)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"metric", "value"})
+		cw.WriteAll(rows)
+		return cw.Error()
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s:\t%s\n", r[0], r[1])
+	}
+	return tw.Flush()
+}
+
+// writeimportresults, tulis hasil import per file
+func writeImportResults(w io.Writer, format string, results []service.ImportResult) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	header := []string{"file", "status", "id", "title", "error"}
+	row := func(r service.ImportResult) []string {
+		return []string{r.File, r.Status, r.ID, r.Title, r.Error}
+	}
+	switch format {
+	case "json":
+		return writeJSON(w, results)
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		for _, r := range results {
+			cw.Write(row(r))
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(header, "\t")))
+	for _, r := range results {
+		fmt.Fprintln(tw, strings.Join(row(r), "\t"))
+	}
+	return tw.Flush()
+}
@@ -1,48 +1,210 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "modernc.org/sqlite"
 
+	"github.com/fhmptrdnd/private-blog/internal/backup"
+	"github.com/fhmptrdnd/private-blog/internal/config"
 	"github.com/fhmptrdnd/private-blog/internal/handler"
+	"github.com/fhmptrdnd/private-blog/internal/metrics"
 	"github.com/fhmptrdnd/private-blog/internal/repository"
 	"github.com/fhmptrdnd/private-blog/internal/service"
 )
 
 func main() {
+	// baca config dari file, env, sama flag
+	cfg, err := config.Load("web", os.Args[1:], os.Getenv)
+	if err != nil {
+		fmt.Printf("failed to load config: %v\n", err)
+		os.Exit(2)
+	}
+	if err := config.Validate(cfg); err != nil {
+		fmt.Printf("invalid config:\n%v\n", err)
+		os.Exit(2)
+	}
+	if cfg.PrintConfig {
+		config.Print(os.Stdout, cfg)
+		return
+	}
+	// robots.txt custom dibaca sekali pas start
+	var robots string
+	if cfg.RobotsFile != "" {
+		b, err := os.ReadFile(cfg.RobotsFile)
+		if err != nil {
+			fmt.Printf("failed to read robots file: %v\n", err)
+			os.Exit(1)
+		}
+		robots = string(b)
+	}
+	if err := handler.CheckTemplates(cfg.TemplateDir); err != nil {
+		fmt.Printf("failed to load templates: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.MkdirAll(cfg.UploadDir, 0o755); err != nil {
+		fmt.Printf("failed to create upload dir: %v\n", err)
+		os.Exit(1)
+	}
+
+	// tandain database lagi dipake, biar backup restore nolak nimpa pas server jalan
+	releaseLock, err := backup.AcquireLock(cfg.DBPath)
+	if err != nil {
+		fmt.Printf("failed to lock database: %v\n", err)
+		os.Exit(1)
+	}
+	defer releaseLock()
+
 	// initialize sqlite database
-	repo, err := repository.NewSQLiteRepo("blog.db")
+	repo, err := repository.NewSQLiteRepoWithOptions(cfg.DBPath, repository.SQLiteOptions{
+		BusyTimeout:  time.Duration(cfg.DB.BusyTimeoutMS) * time.Millisecond,
+		Synchronous:  cfg.DB.Synchronous,
+		MaxReadConns: cfg.DB.ReadConns,
+	})
 	if err != nil {
 		fmt.Printf("failed to initialize database: %v\n", err)
 		return
 	}
-	
+
+	// metrics: latency query database dicatet lewat decorator withtiming
+	reg := metrics.NewRegistry()
+	dbDuration := reg.Histogram("db_query_duration_seconds", "Database query latency by operation.",
+		metrics.DefaultBuckets, "op", "result")
+
+	// decorator dipasang dari luar ke dalam:
+	// timing + slow log ngukur total waktu (termasuk retry),
+	// circuit breaker liat hasil akhir retry, retry paling deket ke sqlite
+	repo = repository.Decorate(repo,
+		repository.WithTiming(func(op string, d time.Duration, err error) {
+			result := "ok"
+			if err != nil {
+				result = "error"
+			}
+			dbDuration.Observe(d.Seconds(), op, result)
+		}),
+		repository.WithSlowQueryLog(200*time.Millisecond, func(format string, args ...any) {
+			fmt.Printf(format, args...)
+		}),
+		repository.WithCircuitBreaker(5, 30*time.Second),
+		repository.WithRetry(3, 50*time.Millisecond),
+	)
+	reg.GaugeFunc("db_open_connections", "Open database connections.", func() float64 {
+		return float64(repo.Stats().OpenConnections)
+	})
+
+	// cache get + batching view increments, dipasang di luar instrument
+	// biar cache hit ga kehitung sebagai query database
+	repo, cache := repository.WithCache(repo, repository.DefaultCacheOptions())
+	reg.CounterFunc("repository_cache_hits_total", "Article cache hits.", func() float64 {
+		return float64(cache.Stats().Hits)
+	})
+	reg.CounterFunc("repository_cache_misses_total", "Article cache misses.", func() float64 {
+		return float64(cache.Stats().Misses)
+	})
+	reg.GaugeFunc("repository_cache_pending_views", "View increments waiting to be flushed.", func() float64 {
+		return float64(cache.Stats().PendingViews)
+	})
+
+	articleEvents := reg.Counter("article_events_total", "Article lifecycle events (created, updated, deleted, view_incremented).", "event")
+
 	// pake function types, bukan struct
 	// clock sama idgen ini function yang bisa dipanggil
 	clock := service.NewRealClock()  // function buat dapetin waktu
 	idGen := service.NewRealIDGen()  // function buat generate id
-	
-	svc := service.NewArticleService(repo, clock, idGen)
-	h := handler.NewHandler(svc)
 
-	// WithLogging: log setiap request
+	svc := service.NewArticleService(repo, clock, idGen).WithEvents(func(event string) {
+		articleEvents.Inc(event)
+	}).WithViewWindow(time.Duration(cfg.ViewWindowMinutes) * time.Minute)
+	h := handler.NewHandler(svc, handler.Options{
+		Cookie: handler.CookieOptions{
+			Name:   cfg.Cookie.Name,
+			MaxAge: cfg.Cookie.MaxAge,
+			Secure: cfg.Cookie.Secure,
+			Domain: cfg.Cookie.Domain,
+		},
+		UploadDir:     cfg.UploadDir,
+		PublicBaseURL: cfg.PublicBaseURL,
+		Robots:        robots,
+		TemplateDir:   cfg.TemplateDir,
+		TemplateDev:   cfg.TemplateDev,
+	})
+
+	// logging: log setiap request (text atau json sesuai config)
+	// WithMetrics: catet jumlah request dan latency per route
+	// WithCompression: gzip response, di luar WithPanicRecovery biar halaman error ikut lewat situ
 	// WithPanicRecovery: tangkap panic biar server ga crash
-	
+	// rateLimit: dibikin sekali biar bucket-nya dipake bareng semua route
+	logging := handler.NewLogging(cfg.LogFormat)
+	rateLimit := handler.WithRateLimit(cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst)
+	httpMetrics := handler.NewHTTPMetrics(reg)
+	compress := handler.WithCompression(0)
+
+	// route, daftarin handler dengan middleware standar, pattern dipake jadi label metrics
+	// cache itu cache-control bawaan route, handler masih bisa nimpa (misal halaman artikel)
+	route := func(pattern, cache string, h http.HandlerFunc) {
+		http.HandleFunc(pattern, handler.Chain(h,
+			logging, handler.WithMetrics(httpMetrics, pattern), compress, handler.WithPanicRecovery, rateLimit,
+			handler.WithCacheControl(cache)))
+	}
+
 	// routes yang cuma butuh GET
-	http.HandleFunc("/", handler.Chain(h.Home, handler.WithLogging, handler.WithPanicRecovery))
-	http.HandleFunc("/my-articles", handler.Chain(h.MyArticles, handler.WithLogging, handler.WithPanicRecovery))
-	http.HandleFunc("/view/", handler.Chain(h.View, handler.WithLogging, handler.WithPanicRecovery))
-	http.HandleFunc("/edit/", handler.Chain(h.Edit, handler.WithLogging, handler.WithPanicRecovery))
-	
+	// halaman milik user cuma boleh di-cache browser-nya sendiri
+	route("/", handler.CachePublic, h.Home)
+	route("/my-articles", handler.CachePrivate, h.MyArticles)
+	route("/my-articles/export", handler.CacheNoStore, h.Export)
+	route("/my-articles/import", handler.CachePrivate, h.Import)
+	route("/view/", handler.CachePrivate, h.View)
+	route("/edit/", handler.CachePrivate, h.Edit)
+	route("/stats/", handler.CachePrivate, h.Stats)
+
 	// routes yang butuh POST (dengan method check)
-	http.HandleFunc("/create", handler.Chain(h.Create, handler.WithLogging, handler.WithPanicRecovery))
-	http.HandleFunc("/update/", handler.Chain(h.Update, handler.WithLogging, handler.WithPanicRecovery))
-	http.HandleFunc("/delete/", handler.Chain(h.Delete, handler.WithLogging, handler.WithPanicRecovery))
+	route("/create", handler.CacheNoStore, h.Create)
+	route("/update/", handler.CacheNoStore, h.Update)
+	route("/delete/", handler.CacheNoStore, h.Delete)
+	route("/preview", handler.CacheNoStore, h.Preview)
 
-	fmt.Println("Telegraph running at http://localhost:8080")
-	if err := http.ListenAndServe(":8080", nil); err != nil {
+	// api kompatibel telegraph (GET atau POST, method dicek di dalam)
+	route("/api/", handler.CacheNoStore, h.API)
+
+	// buat mesin pencari
+	// cache-control-nya dipasang sendiri sama handler
+	route("/sitemap.xml", handler.CachePublic, h.Sitemap)
+	route("/sitemaps/", handler.CachePublic, h.Sitemap)
+	route("/robots.txt", handler.CachePublic, h.Robots)
+
+	// aset statis, ga pake rate limit soalnya satu halaman bisa narik beberapa file sekaligus
+	http.HandleFunc("/static/", handler.Chain(h.Static,
+		handler.WithMetrics(httpMetrics, "/static/"), handler.WithPanicRecovery))
+
+	// monitoring, ga pake logging sama rate limit biar ga nyampah
+	http.HandleFunc("/healthz", handler.Chain(h.Healthz, handler.WithPanicRecovery))
+	http.HandleFunc("/readyz", handler.Chain(h.Readyz, handler.WithPanicRecovery))
+	http.HandleFunc("/metrics", handler.Chain(reg.Handler(), handler.WithPanicRecovery))
+
+	server := &http.Server{Addr: cfg.Addr}
+
+	// shutdown rapi pas dapet sinyal, biar views yang ketahan di cache ke-flush
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	fmt.Printf("Telegraph running at %s\n", cfg.Addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		fmt.Println("server error:", err)
 	}
-}
\ No newline at end of file
+	// close cache = flush views yang ketahan, terus lepas statement sama koneksi database
+	if err := repo.Close(); err != nil {
+		fmt.Println("failed to close repository:", err)
+	}
+}
@@ -2,7 +2,10 @@ module github.com/fhmptrdnd/private-blog
 
 go 1.24.0
 
-require modernc.org/sqlite v1.40.1
+require (
+	github.com/andybalholm/brotli v1.2.0
+	modernc.org/sqlite v1.40.1
+)
 
 require (
 	github.com/dustin/go-humanize v1.0.1 // indirect
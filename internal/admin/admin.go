@@ -0,0 +1,269 @@
+// package admin, operasi maintenance database yang ga ada di repository
+// (liat artikel yang udah dihapus, restore, purge, pindah owner, statistik)
+// dipake cmd/dbview, langsung ke sqlite karena ini alat operator, bukan bagian aplikasi
+package admin
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+	_ "modernc.org/sqlite"
+)
+
+// deletedfilter, cara nampilin artikel yang udah di-soft delete
+type DeletedFilter string
+
+const (
+	DeletedExclude DeletedFilter = "exclude" // cuma yang aktif
+	DeletedInclude DeletedFilter = "include" // aktif + terhapus
+	DeletedOnly    DeletedFilter = "only"    // cuma yang terhapus
+)
+
+// filter, kriteria buat list
+type Filter struct {
+	Owner   string
+	Author  string
+	Deleted DeletedFilter
+	Since   time.Time // created_at >= since (zero = ga dibatasin)
+	Until   time.Time // created_at < until (zero = ga dibatasin)
+}
+
+// open, buka database buat operasi admin
+func Open(dbPath string) (*sql.DB, error) {
+	// path yang salah jadi error, bukan bikin database kosong baru
+	if _, err := os.Stat(dbPath); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	// schema harus udah sama kayak versi aplikasi biar query di sini ga nyasar ke kolom yang belum ada
+	// migration ga dijalanin dari sini, alat operator ga boleh ngubah schema di belakang server
+	if err := repository.CheckSchema(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+const selectColumns = `SELECT id, title, author, content, created_at, updated_at, views, owner_id, deleted_at,
+	word_count, reading_minutes, excerpt, theme FROM articles`
+
+// scanarticle, baca satu baris jadi artikel
+func scanArticle(scan func(...any) error) (models.Article, error) {
+	var a models.Article
+	err := scan(&a.ID, &a.Title, &a.Author, &a.Content,
+		&a.CreatedAt, &a.UpdatedAt, &a.Views, &a.OwnerID, &a.DeletedAt,
+		&a.WordCount, &a.ReadingMinutes, &a.Excerpt, &a.Theme)
+	return a, err
+}
+
+// matches, predicate buat filter yang ga bisa dilakuin di sql
+// (tanggal disimpen sebagai teks dengan zona waktu, jadi dibandingin di go)
+func (f Filter) matches(a models.Article) bool {
+	if !f.Since.IsZero() && a.CreatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !a.CreatedAt.Before(f.Until) {
+		return false
+	}
+	return true
+}
+
+// list, ambil artikel sesuai filter, yang terbaru duluan
+func List(db *sql.DB, f Filter) ([]models.Article, error) {
+	query := selectColumns + ` WHERE 1 = 1`
+	var args []any
+	if f.Owner != "" {
+		query += ` AND owner_id = ?`
+		args = append(args, f.Owner)
+	}
+	if f.Author != "" {
+		query += ` AND author = ?`
+		args = append(args, f.Author)
+	}
+	switch f.Deleted {
+	case DeletedInclude:
+	case DeletedOnly:
+		query += ` AND deleted_at IS NOT NULL`
+	case DeletedExclude, "":
+		query += ` AND deleted_at IS NULL`
+	default:
+		return nil, fmt.Errorf("deleted filter %q ga dikenal (exclude/include/only)", f.Deleted)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []models.Article
+	for rows.Next() {
+		a, err := scanArticle(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		if f.matches(a) {
+			articles = append(articles, a)
+		}
+	}
+	return articles, rows.Err()
+}
+
+// show, ambil satu artikel lengkap termasuk yang udah dihapus
+func Show(db *sql.DB, id string) (models.Article, error) {
+	a, err := scanArticle(db.QueryRow(selectColumns+` WHERE id = ?`, id).Scan)
+	if err == sql.ErrNoRows {
+		return models.Article{}, repository.ErrNotFound
+	}
+	return a, err
+}
+
+// restore, batalin soft delete
+func Restore(db *sql.DB, id string) error {
+	result, err := db.Exec(`UPDATE articles SET deleted_at = NULL WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	return affected(result, err)
+}
+
+// purge, hapus permanen artikel yang udah di-soft delete sebelum cutoff
+// kalo dryrun, cuma return daftar yang bakal dihapus
+func Purge(db *sql.DB, cutoff time.Time, dryRun bool) ([]models.Article, error) {
+	candidates, err := List(db, Filter{Deleted: DeletedOnly})
+	if err != nil {
+		return nil, err
+	}
+	var victims []models.Article
+	for _, a := range candidates {
+		if a.DeletedAt != nil && a.DeletedAt.Before(cutoff) {
+			victims = append(victims, a)
+		}
+	}
+	if dryRun || len(victims) == 0 {
+		return victims, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range victims {
+		// statistik tayangannya ikut dibuang, artikelnya udah ga bakal balik lagi
+		for _, q := range []string{
+			`DELETE FROM article_daily_views WHERE article_id = ?`,
+			`DELETE FROM article_referrers WHERE article_id = ?`,
+			`DELETE FROM articles WHERE id = ? AND deleted_at IS NOT NULL`,
+		} {
+			if _, err := tx.Exec(q, a.ID); err != nil {
+				tx.Rollback()
+				return nil, err
+			}
+		}
+	}
+	return victims, tx.Commit()
+}
+
+// recomputemetadata, hitung ulang metadata semua artikel (termasuk yang terhapus) pake compute
+// compute dikasih dari luar (service.WithMetadata) biar aturan hitungnya cuma ada di satu tempat
+// return jumlah artikel yang metadatanya berubah
+func RecomputeMetadata(db *sql.DB, compute func(models.Article) models.Article) (int, error) {
+	articles, err := List(db, Filter{Deleted: DeletedInclude})
+	if err != nil {
+		return 0, err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	changed := 0
+	for _, a := range articles {
+		m := compute(a)
+		if m.WordCount == a.WordCount && m.ReadingMinutes == a.ReadingMinutes && m.Excerpt == a.Excerpt {
+			continue
+		}
+		_, err := tx.Exec(`UPDATE articles SET word_count = ?, reading_minutes = ?, excerpt = ? WHERE id = ?`,
+			m.WordCount, m.ReadingMinutes, m.Excerpt, a.ID)
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		changed++
+	}
+	return changed, tx.Commit()
+}
+
+// reassignowner, pindahin semua artikel dari satu owner ke owner lain
+// return jumlah artikel yang dipindah
+func ReassignOwner(db *sql.DB, from, to string) (int64, error) {
+	if from == "" || to == "" {
+		return 0, fmt.Errorf("owner asal dan tujuan ga boleh kosong")
+	}
+	result, err := db.Exec(`UPDATE articles SET owner_id = ? WHERE owner_id = ?`, to, from)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// stats, ringkasan isi database
+type Stats struct {
+	Active     int    `json:"active"`
+	Deleted    int    `json:"deleted"`
+	Owners     int    `json:"owners"`
+	Authors    int    `json:"authors"`
+	TotalViews int    `json:"total_views"`
+	TopID      string `json:"top_id,omitempty"`
+	TopTitle   string `json:"top_title,omitempty"`
+	TopViews   int    `json:"top_views"`
+}
+
+// collectstats, hitung statistik dari semua artikel
+func CollectStats(db *sql.DB) (Stats, error) {
+	var s Stats
+	err := db.QueryRow(`
+		SELECT
+			COUNT(CASE WHEN deleted_at IS NULL THEN 1 END),
+			COUNT(CASE WHEN deleted_at IS NOT NULL THEN 1 END),
+			COUNT(DISTINCT CASE WHEN deleted_at IS NULL THEN owner_id END),
+			COUNT(DISTINCT CASE WHEN deleted_at IS NULL THEN author END),
+			COALESCE(SUM(CASE WHEN deleted_at IS NULL THEN views END), 0)
+		FROM articles
+	`).Scan(&s.Active, &s.Deleted, &s.Owners, &s.Authors, &s.TotalViews)
+	if err != nil {
+		return Stats{}, err
+	}
+	err = db.QueryRow(`
+		SELECT id, title, views FROM articles
+		WHERE deleted_at IS NULL
+		ORDER BY views DESC LIMIT 1
+	`).Scan(&s.TopID, &s.TopTitle, &s.TopViews)
+	if err != nil && err != sql.ErrNoRows {
+		return Stats{}, err
+	}
+	return s, nil
+}
+
+// affected, errnotfound kalo ga ada baris yang berubah
+func affected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
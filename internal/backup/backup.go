@@ -0,0 +1,314 @@
+// package backup, snapshot dan restore database sqlite selagi server jalan
+package backup
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// errserverrunning, restore ditolak karena database lagi dipake server
+var ErrServerRunning = errors.New("database is in use by a running server")
+
+// options, setting buat bikin backup
+type Options struct {
+	Dir      string // folder tujuan backup
+	Compress bool   // gzip hasilnya
+	Keep     int    // simpan n backup terbaru, 0 = simpan semua
+}
+
+// timeformat, format waktu di nama file backup (urut kalo di-sort string)
+const timeFormat = "20060102-150405.000"
+
+// stamp, waktu buat nama file ditambah akhiran acak
+// dua backup di milidetik yang sama (misal dua cron barengan) ga saling timpa
+func stamp(now time.Time) string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return now.UTC().Format(timeFormat) + "-" + hex.EncodeToString(b)
+}
+
+// prefix, awalan nama file backup dari nama database (blog.db -> blog-)
+func prefix(dbPath string) string {
+	base := filepath.Base(dbPath)
+	return strings.TrimSuffix(base, filepath.Ext(base)) + "-"
+}
+
+// snapshot, bikin salinan konsisten dari database pake vacuum into
+// aman dijalanin pas server lagi nulis karena vacuum into baca dalam satu transaksi
+// hasilnya dicek integrity-nya dulu sebelum dianggap jadi
+func Snapshot(dbPath string, opts Options, now time.Time) (string, error) {
+	if opts.Dir == "" {
+		opts.Dir = "."
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return "", err
+	}
+
+	name := prefix(dbPath) + stamp(now) + ".db"
+	final := filepath.Join(opts.Dir, name)
+	tmp := final + ".tmp"
+
+	if err := vacuumInto(dbPath, tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := Verify(tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	if opts.Compress {
+		final += ".gz"
+		if err := gzipFile(tmp, final); err != nil {
+			os.Remove(tmp)
+			return "", err
+		}
+		os.Remove(tmp)
+	} else if err := os.Rename(tmp, final); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	if opts.Keep > 0 {
+		if _, err := Rotate(opts.Dir, prefix(dbPath), opts.Keep); err != nil {
+			return final, fmt.Errorf("backup created but rotation failed: %w", err)
+		}
+	}
+	return final, nil
+}
+
+// vacuuminto, salin database ke file baru lewat koneksi biasa
+func vacuumInto(dbPath, dest string) error {
+	if _, err := os.Stat(dbPath); err != nil {
+		return err
+	}
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if _, err := db.Exec(`VACUUM INTO ?`, dest); err != nil {
+		return fmt.Errorf("vacuum into: %w", err)
+	}
+	return nil
+}
+
+// verify, jalanin integrity_check ke file database (boleh .gz)
+func Verify(path string) error {
+	if strings.HasSuffix(path, ".gz") {
+		tmp, err := gunzipToTemp(path, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp)
+		path = tmp
+	}
+
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rows, err := db.Query(`PRAGMA integrity_check`)
+	if err != nil {
+		return fmt.Errorf("integrity check %s: %w", path, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check %s failed: %s", path, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// isbackupname, nama file persis bikinan snapshot: prefix + waktu + "-" + 6 hex + .db/.db.gz
+// file lain di folder yang sama (misal blog-old.db) ga boleh ikut kerotasi
+func isBackupName(name, filePrefix string) bool {
+	rest, ok := strings.CutPrefix(name, filePrefix)
+	if !ok {
+		return false
+	}
+	if r, ok := strings.CutSuffix(rest, ".db.gz"); ok {
+		rest = r
+	} else if rest, ok = strings.CutSuffix(rest, ".db"); !ok {
+		return false
+	}
+	// timeformat sendiri ada "-", jadi dipotong sesuai panjangnya
+	if len(rest) != len(timeFormat)+1+6 || rest[len(timeFormat)] != '-' {
+		return false
+	}
+	if _, err := time.Parse(timeFormat, rest[:len(timeFormat)]); err != nil {
+		return false
+	}
+	suffix := rest[len(timeFormat)+1:]
+	_, err := hex.DecodeString(suffix)
+	return err == nil && suffix == strings.ToLower(suffix)
+}
+
+// rotate, hapus backup lama, sisain keep file terbaru
+// return daftar file yang dihapus
+func Rotate(dir, filePrefix string, keep int) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, e := range entries {
+		if !e.IsDir() && isBackupName(e.Name(), filePrefix) {
+			backups = append(backups, e.Name())
+		}
+	}
+	// nama file isinya timestamp, jadi urut string = urut waktu (terbaru di belakang)
+	sort.Strings(backups)
+
+	var removed []string
+	for len(backups) > keep {
+		path := filepath.Join(dir, backups[0])
+		if err := os.Remove(path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, path)
+		backups = backups[1:]
+	}
+	return removed, nil
+}
+
+// restore, timpa database dengan isi backup
+// ditolak kalo server lagi jalan (lock file ada dan prosesnya hidup)
+// file -wal sisa (misal server crash) juga bikin ditolak, kecuali force
+// database lama ga dihapus, dipindah ke <db>.pre-restore-<waktu> buat jaga-jaga
+func Restore(src, dbPath string, force bool, now time.Time) (string, error) {
+	if pid, running := Running(dbPath); running {
+		return "", fmt.Errorf("%w (pid %d)", ErrServerRunning, pid)
+	}
+	_, walErr := os.Stat(dbPath + "-wal")
+	hasWAL := walErr == nil
+	if hasWAL && !force {
+		return "", fmt.Errorf("%s-wal exists, database may still be open: %w", dbPath, ErrServerRunning)
+	}
+
+	// siapin file hasil restore di folder yang sama biar rename-nya atomik
+	dir := filepath.Dir(dbPath)
+	var tmp string
+	var err error
+	if strings.HasSuffix(src, ".gz") {
+		tmp, err = gunzipToTemp(src, dir)
+	} else {
+		tmp, err = copyToTemp(src, dir)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := Verify(tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+
+	previous := ""
+	if _, err := os.Stat(dbPath); err == nil {
+		previous = dbPath + ".pre-restore-" + stamp(now)
+		if err := os.Rename(dbPath, previous); err != nil {
+			os.Remove(tmp)
+			return "", err
+		}
+		// wal sisa ikut dipindah, jangan sampe ke-apply ke database hasil restore
+		if hasWAL {
+			if err := os.Rename(dbPath+"-wal", previous+"-wal"); err != nil {
+				os.Remove(tmp)
+				return previous, err
+			}
+		}
+	}
+	if err := os.Rename(tmp, dbPath); err != nil {
+		os.Remove(tmp)
+		return previous, err
+	}
+	os.Remove(dbPath + "-shm")
+	return previous, nil
+}
+
+// gzipfile, kompres src ke dest
+func gzipFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	_, err = io.Copy(zw, in)
+	err = errors.Join(err, zw.Close(), out.Sync(), out.Close())
+	if err != nil {
+		os.Remove(dest)
+	}
+	return err
+}
+
+// gunziptotemp, buka file .gz ke file sementara di dir
+func gunzipToTemp(src, dir string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	zr, err := gzip.NewReader(in)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", src, err)
+	}
+	defer zr.Close()
+	return writeTemp(zr, dir)
+}
+
+// copytotemp, salin file ke file sementara di dir
+func copyToTemp(src, dir string) (string, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return "", err
+	}
+	defer in.Close()
+	return writeTemp(in, dir)
+}
+
+// writetemp, tulis isi reader ke file sementara, return path-nya
+func writeTemp(r io.Reader, dir string) (string, error) {
+	out, err := os.CreateTemp(dir, ".restore-*.db")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(out, r)
+	if err = errors.Join(err, out.Sync(), out.Close()); err != nil {
+		os.Remove(out.Name())
+		return "", err
+	}
+	return out.Name(), nil
+}
@@ -0,0 +1,61 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// file lain yang kebetulan awalannya sama kayak backup (blog-old.db dll) ga boleh ikut kehapus rotate
+func TestRotateKeepsUnrelatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var backups []string
+	for i := 0; i < 3; i++ {
+		backups = append(backups, prefix("blog.db")+stamp(base.Add(time.Duration(i)*time.Second))+".db")
+	}
+	backups = append(backups, prefix("blog.db")+stamp(base.Add(time.Hour))+".db.gz")
+	unrelated := []string{
+		"blog-old.db",
+		"blog-test.db.gz",
+		"blog-20240101-000000.000.db",        // tanpa akhiran acak
+		"blog-20240101-000000.000-xyz123.db", // akhiran bukan hex
+		"blog-20241301-000000.000-1a2b3c.db", // bulan ga valid
+		"blog.db",
+	}
+	for _, name := range append(append([]string{}, backups...), unrelated...) {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := Rotate(dir, prefix("blog.db"), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 3 {
+		t.Fatalf("removed %v, want the 3 oldest backups", removed)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	want := append([]string{backups[3]}, unrelated...)
+	sort.Strings(want)
+	sort.Strings(left)
+	if len(left) != len(want) {
+		t.Fatalf("left %v, want %v", left, want)
+	}
+	for i := range want {
+		if left[i] != want[i] {
+			t.Fatalf("left %v, want %v", left, want)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// freshlock, lock file kosong yang umurnya di bawah ini dianggap lagi ditulis, bukan rusak
+const freshLock = 5 * time.Second
+
+// lockpath, file pid yang nandain database lagi dipake server
+func lockPath(dbPath string) string {
+	return dbPath + ".pid"
+}
+
+// acquirelock, tandain database lagi dipake proses ini (dipanggil cmd/web pas start)
+// file lock dibikin pake o_excl, jadi dari dua proses yang barengan cuma satu yang dapet
+// lock dari proses lain yang masih hidup bikin gagal, lock basi dari proses mati diambil alih
+// return function buat lepas lock-nya
+func AcquireLock(dbPath string) (func() error, error) {
+	path := lockPath(dbPath)
+	release := func() error {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	// percobaan kedua cuma kejadian setelah lock basi disingkirin
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			_, werr := f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+			if err := errors.Join(werr, f.Close()); err != nil {
+				os.Remove(path)
+				return nil, err
+			}
+			return release, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if err := removeStaleLock(path); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s keburu diambil proses lain", ErrServerRunning, path)
+}
+
+// removestalelock, singkirin lock file yang pid-nya udah mati
+// file-nya dipindah dulu (rename atomik) terus dicek ulang isinya, jadi kalo ternyata
+// proses lain barusan bikin lock baru di situ, lock itu dibalikin dan ga ikut kehapus
+func removeStaleLock(path string) error {
+	stale, ok := readPid(path)
+	if ok && stale != os.Getpid() && processAlive(stale) {
+		return fmt.Errorf("%w (pid %d)", ErrServerRunning, stale)
+	}
+	// isinya kosong bisa jadi proses lain baru aja bikin dan belum sempet nulis pid
+	if fi, err := os.Stat(path); !ok && err == nil && time.Since(fi.ModTime()) < freshLock {
+		return fmt.Errorf("%w: %s baru dibikin", ErrServerRunning, path)
+	}
+	moved := fmt.Sprintf("%s.stale-%d", path, os.Getpid())
+	if err := os.Rename(path, moved); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil // udah disingkirin proses lain
+		}
+		return err
+	}
+	if pid, ok := readPid(moved); ok && pid != stale && processAlive(pid) {
+		// link gagal kalo udah ada lock lain lagi, yang penting lock yang idup ga ilang diem-diem
+		os.Link(moved, path)
+		os.Remove(moved)
+		return fmt.Errorf("%w (pid %d)", ErrServerRunning, pid)
+	}
+	return os.Remove(moved)
+}
+
+// running, cek ada server yang lagi megang database
+// return pid dari lock file sama status prosesnya masih hidup atau ngga
+func Running(dbPath string) (int, bool) {
+	pid, ok := readPid(lockPath(dbPath))
+	if !ok {
+		return 0, false
+	}
+	return pid, processAlive(pid)
+}
+
+// readpid, baca pid dari lock file, ok false kalo file-nya ga ada atau isinya rusak
+func readPid(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
+// processalive, kirim sinyal 0 buat ngecek proses masih ada (ga beneran ngirim apa-apa)
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	// eperm artinya prosesnya ada tapi punya user lain
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
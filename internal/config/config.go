@@ -0,0 +1,355 @@
+// package config, tempat semua konfigurasi aplikasi dikumpulin
+// urutan prioritas: default < file json/toml < environment variable < flag
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// config, semua setting yang dipake cmd/web sama cmd/dbview
+type Config struct {
+	Addr      string          `json:"addr"`
+	DBPath    string          `json:"db_path"`
+	DB        DBConfig        `json:"db"`
+	Cookie    CookieConfig    `json:"cookie"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	UploadDir string          `json:"upload_dir"`
+	LogFormat string          `json:"log_format"` // "text" atau "json"
+
+	// viewwindowminutes, pengunjung yang sama baru dihitung lagi setelah sekian menit, 0 = tiap kunjungan dihitung
+	ViewWindowMinutes int `json:"view_window_minutes"`
+
+	// publicbaseurl, alamat publik situs (misal https://blog.example.com) buat url absolut
+	// kosong = ditebak dari host request, ga aman di belakang proxy yang ganti host
+	PublicBaseURL string `json:"public_base_url"`
+
+	// robotsfile, file robots.txt buat nimpa bawaan, kosong = pake robots.txt bawaan
+	RobotsFile string `json:"robots_file"`
+
+	// templatedir, folder template yang nimpa bawaan, templatedev parse ulang tiap request
+	TemplateDir string `json:"template_dir"`
+	TemplateDev bool   `json:"template_dev"`
+
+	// field di bawah cuma dari flag/env, ga ikut di-print
+	ConfigFile  string   `json:"-"`
+	PrintConfig bool     `json:"-"`
+	Args        []string `json:"-"` // sisa argumen setelah flag (buat subcommand)
+}
+
+// dbconfig, tuning koneksi sqlite
+type DBConfig struct {
+	BusyTimeoutMS int    `json:"busy_timeout_ms"`
+	Synchronous   string `json:"synchronous"` // OFF, NORMAL, FULL, EXTRA
+	ReadConns     int    `json:"read_conns"`
+}
+
+// cookieconfig, setting cookie kepemilikan artikel
+type CookieConfig struct {
+	Name   string `json:"name"`
+	MaxAge int    `json:"max_age"` // detik
+	Secure bool   `json:"secure"`
+	Domain string `json:"domain"`
+}
+
+// ratelimitconfig, batas request per ip, 0 artinya ga dibatasin
+type RateLimitConfig struct {
+	RequestsPerMinute int `json:"requests_per_minute"`
+	Burst             int `json:"burst"`
+}
+
+// default, nilai awal sebelum ditimpa file/env/flag
+func Default() Config {
+	return Config{
+		Addr:   ":8080",
+		DBPath: "blog.db",
+		DB: DBConfig{
+			BusyTimeoutMS: 5000,
+			Synchronous:   "NORMAL",
+			ReadConns:     4,
+		},
+		Cookie: CookieConfig{
+			Name:   "user_id",
+			MaxAge: 31536000 * 10,
+		},
+		UploadDir: "uploads",
+		LogFormat: "text",
+
+		ViewWindowMinutes: 30,
+	}
+}
+
+// setting, satu baris konfigurasi: nama flag, nama env, sama function buat set nilainya
+// ini contoh "function as data", tiap setting bawa setter-nya sendiri
+type setting struct {
+	flag   string
+	env    string
+	usage  string
+	isBool bool
+	set    func(*Config, string) error
+}
+
+// stringsetting, bikin setter buat field string
+func stringSetting(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		*field(c) = v
+		return nil
+	}
+}
+
+// intsetting, bikin setter buat field int
+func intSetting(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("bukan angka: %q", v)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// boolsetting, bikin setter buat field bool
+func boolSetting(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("bukan boolean: %q", v)
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+// settings, daftar semua setting yang bisa diatur dari env sama flag
+var settings = []setting{
+	{flag: "addr", env: "BLOG_ADDR", usage: "alamat listen http",
+		set: stringSetting(func(c *Config) *string { return &c.Addr })},
+	{flag: "db", env: "BLOG_DB_PATH", usage: "path file database sqlite",
+		set: stringSetting(func(c *Config) *string { return &c.DBPath })},
+	{flag: "db-busy-timeout", env: "BLOG_DB_BUSY_TIMEOUT_MS", usage: "berapa ms nunggu lock database",
+		set: intSetting(func(c *Config) *int { return &c.DB.BusyTimeoutMS })},
+	{flag: "db-synchronous", env: "BLOG_DB_SYNCHRONOUS", usage: "pragma synchronous: OFF, NORMAL, FULL, EXTRA",
+		set: stringSetting(func(c *Config) *string { return &c.DB.Synchronous })},
+	{flag: "db-read-conns", env: "BLOG_DB_READ_CONNS", usage: "jumlah koneksi baca ke database",
+		set: intSetting(func(c *Config) *int { return &c.DB.ReadConns })},
+	{flag: "cookie-name", env: "BLOG_COOKIE_NAME", usage: "nama cookie kepemilikan",
+		set: stringSetting(func(c *Config) *string { return &c.Cookie.Name })},
+	{flag: "cookie-max-age", env: "BLOG_COOKIE_MAX_AGE", usage: "umur cookie dalam detik",
+		set: intSetting(func(c *Config) *int { return &c.Cookie.MaxAge })},
+	{flag: "cookie-secure", env: "BLOG_COOKIE_SECURE", usage: "kirim cookie cuma lewat https", isBool: true,
+		set: boolSetting(func(c *Config) *bool { return &c.Cookie.Secure })},
+	{flag: "cookie-domain", env: "BLOG_COOKIE_DOMAIN", usage: "domain cookie (kosong = host saat ini)",
+		set: stringSetting(func(c *Config) *string { return &c.Cookie.Domain })},
+	{flag: "rate-limit", env: "BLOG_RATE_LIMIT_RPM", usage: "maksimal request per menit per ip (0 = mati)",
+		set: intSetting(func(c *Config) *int { return &c.RateLimit.RequestsPerMinute })},
+	{flag: "rate-burst", env: "BLOG_RATE_LIMIT_BURST", usage: "jumlah request burst yang diizinkan",
+		set: intSetting(func(c *Config) *int { return &c.RateLimit.Burst })},
+	{flag: "view-window", env: "BLOG_VIEW_WINDOW_MINUTES", usage: "menit sebelum pengunjung yang sama dihitung lagi (0 = tiap kunjungan)",
+		set: intSetting(func(c *Config) *int { return &c.ViewWindowMinutes })},
+	{flag: "upload-dir", env: "BLOG_UPLOAD_DIR", usage: "folder buat file upload",
+		set: stringSetting(func(c *Config) *string { return &c.UploadDir })},
+	{flag: "log-format", env: "BLOG_LOG_FORMAT", usage: "format log: text atau json",
+		set: stringSetting(func(c *Config) *string { return &c.LogFormat })},
+	{flag: "public-base-url", env: "BLOG_PUBLIC_BASE_URL", usage: "alamat publik situs buat canonical url (kosong = dari request)",
+		set: stringSetting(func(c *Config) *string { return &c.PublicBaseURL })},
+	{flag: "robots-file", env: "BLOG_ROBOTS_FILE", usage: "file robots.txt (kosong = bawaan)",
+		set: stringSetting(func(c *Config) *string { return &c.RobotsFile })},
+	{flag: "template-dir", env: "BLOG_TEMPLATE_DIR", usage: "folder template buat nimpa tampilan bawaan",
+		set: stringSetting(func(c *Config) *string { return &c.TemplateDir })},
+	{flag: "template-dev", env: "BLOG_TEMPLATE_DEV", usage: "parse ulang template tiap request (buat development)", isBool: true,
+		set: boolSetting(func(c *Config) *bool { return &c.TemplateDev })},
+}
+
+// only, ambil sebagian settings berdasarkan nama flag
+func only(names ...string) []setting {
+	var out []setting
+	for _, s := range settings {
+		for _, n := range names {
+			if s.flag == n {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// dbsettings, setting yang dipake cli admin (dbview, backup), cuma path database
+var dbSettings = only("db")
+
+// load, baca konfigurasi dari default, file, env, terus flag (yang terakhir menang)
+// getenv dijadiin parameter biar gampang diganti, biasanya os.Getenv
+func Load(name string, args []string, getenv func(string) string) (Config, error) {
+	return load(name, args, getenv, settings)
+}
+
+// loaddb, versi load buat cli admin: flag-nya cuma -config, -print-config, sama -db
+// jadi -h ga nampilin flag server, file config yang sama kayak cmd/web tetep bisa dipake
+func LoadDB(name string, args []string, getenv func(string) string) (Config, error) {
+	return load(name, args, getenv, dbSettings)
+}
+
+// load, isi load/loaddb, settings nentuin flag sama env apa aja yang dikenal
+func load(name string, args []string, getenv func(string) string, settings []setting) (Config, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	// nilai flag ditampung dulu, baru di-apply setelah file sama env
+	flagValues := map[string]string{}
+	configFile := fs.String("config", getenv("BLOG_CONFIG"), "path file konfigurasi (.json atau .toml)")
+	printConfig := fs.Bool("print-config", false, "tampilkan konfigurasi akhir terus keluar")
+	for _, s := range settings {
+		record := func(v string) error {
+			flagValues[s.flag] = v
+			return nil
+		}
+		if s.isBool {
+			fs.BoolFunc(s.flag, s.usage, record)
+		} else {
+			fs.Func(s.flag, s.usage, record)
+		}
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+
+	// layer 1: file json/toml
+	if *configFile != "" {
+		if err := loadFile(&cfg, *configFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	// layer 2: environment variable
+	for _, s := range settings {
+		if v := getenv(s.env); v != "" {
+			if err := s.set(&cfg, v); err != nil {
+				return Config{}, fmt.Errorf("env %s: %w", s.env, err)
+			}
+		}
+	}
+
+	// layer 3: flag
+	for _, s := range settings {
+		if v, ok := flagValues[s.flag]; ok {
+			if err := s.set(&cfg, v); err != nil {
+				return Config{}, fmt.Errorf("flag -%s: %w", s.flag, err)
+			}
+		}
+	}
+
+	cfg.ConfigFile = *configFile
+	cfg.PrintConfig = *printConfig
+	cfg.Args = fs.Args()
+	return cfg, nil
+}
+
+// loadfile, timpa config dengan isi file json atau toml (dilihat dari ekstensi .toml)
+// field yang ga ada di file tetep pake nilai sebelumnya
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("buka file config: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		// toml diubah ke json dulu, jadi nama key sama pengecekan field-nya sama persis
+		if data, err = tomlToJSON(data); err != nil {
+			return fmt.Errorf("parse file config %s: %w", path, err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(cfg); err != nil {
+		return fmt.Errorf("parse file config %s: %w", path, err)
+	}
+	return nil
+}
+
+// validate, cek config masuk akal sebelum server jalan
+// semua error dikumpulin biar operator langsung tau semua yang salah
+func Validate(c Config) error {
+	var errs []error
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("addr %q ga valid: %w", c.Addr, err))
+	}
+	if strings.TrimSpace(c.DBPath) == "" {
+		errs = append(errs, errors.New("db_path ga boleh kosong"))
+	}
+	if c.DB.BusyTimeoutMS < 0 {
+		errs = append(errs, errors.New("db.busy_timeout_ms ga boleh negatif"))
+	}
+	switch strings.ToUpper(c.DB.Synchronous) {
+	case "OFF", "NORMAL", "FULL", "EXTRA":
+	default:
+		errs = append(errs, fmt.Errorf("db.synchronous %q ga dikenal (OFF/NORMAL/FULL/EXTRA)", c.DB.Synchronous))
+	}
+	if c.DB.ReadConns < 1 {
+		errs = append(errs, errors.New("db.read_conns minimal 1"))
+	}
+	if !validCookieName(c.Cookie.Name) {
+		errs = append(errs, fmt.Errorf("cookie.name %q ga valid", c.Cookie.Name))
+	}
+	if c.Cookie.MaxAge < 0 {
+		errs = append(errs, errors.New("cookie.max_age ga boleh negatif"))
+	}
+	if c.RateLimit.RequestsPerMinute < 0 || c.RateLimit.Burst < 0 {
+		errs = append(errs, errors.New("rate_limit ga boleh negatif"))
+	}
+	if c.ViewWindowMinutes < 0 {
+		errs = append(errs, errors.New("view_window_minutes ga boleh negatif"))
+	}
+	if strings.TrimSpace(c.UploadDir) == "" {
+		errs = append(errs, errors.New("upload_dir ga boleh kosong"))
+	}
+	if c.LogFormat != "text" && c.LogFormat != "json" {
+		errs = append(errs, fmt.Errorf("log_format %q ga dikenal (text/json)", c.LogFormat))
+	}
+	if c.PublicBaseURL != "" {
+		u, err := url.Parse(c.PublicBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			errs = append(errs, fmt.Errorf("public_base_url %q harus url http(s) absolut tanpa query", c.PublicBaseURL))
+		}
+	}
+	if c.RobotsFile != "" {
+		if _, err := os.Stat(c.RobotsFile); err != nil {
+			errs = append(errs, fmt.Errorf("robots_file: %w", err))
+		}
+	}
+	if c.TemplateDir != "" {
+		if fi, err := os.Stat(c.TemplateDir); err != nil {
+			errs = append(errs, fmt.Errorf("template_dir: %w", err))
+		} else if !fi.IsDir() {
+			errs = append(errs, fmt.Errorf("template_dir %q bukan folder", c.TemplateDir))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validcookiename, nama cookie harus token http yang valid
+func validCookieName(name string) bool {
+	if name == "" {
+		return false
+	}
+	// pinjem validasi dari net/http, cookie yang namanya ga valid bakal kosong pas di-string
+	return (&http.Cookie{Name: name, Value: "x"}).String() != ""
+}
+
+// print, tulis config akhir dalam bentuk json (buat --print-config)
+func Print(w io.Writer, c Config) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// tomltojson, ubah file toml jadi json biar bisa lewat decoder yang sama kayak file json
+// yang didukung cuma subset yang kepake buat config: [tabel], key = nilai, string, angka bulat, boolean, komentar #
+// array, tanggal, string multi-baris, sama inline table ditolak dengan error yang jelas
+func tomlToJSON(data []byte) ([]byte, error) {
+	root := map[string]any{}
+	table := root
+	for i, line := range strings.Split(string(data), "\n") {
+		n := i + 1
+		line = strings.TrimSpace(stripComment(line))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("baris %d: header tabel ga valid: %s", n, line)
+			}
+			t, err := tomlTable(root, strings.TrimSpace(line[1:len(line)-1]), true)
+			if err != nil {
+				return nil, fmt.Errorf("baris %d: %w", n, err)
+			}
+			table = t
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("baris %d: harusnya key = nilai", n)
+		}
+		value, err := tomlValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("baris %d: %w", n, err)
+		}
+
+		// key bertitik (cookie.name = ...) diperlakukan kayak tabel
+		parts := strings.Split(strings.TrimSpace(key), ".")
+		t := table
+		if len(parts) > 1 {
+			if t, err = tomlTable(t, strings.Join(parts[:len(parts)-1], "."), false); err != nil {
+				return nil, fmt.Errorf("baris %d: %w", n, err)
+			}
+		}
+		last := strings.TrimSpace(parts[len(parts)-1])
+		if !bareKey(last) {
+			return nil, fmt.Errorf("baris %d: key %q ga valid", n, last)
+		}
+		if _, dup := t[last]; dup {
+			return nil, fmt.Errorf("baris %d: key %q udah diisi", n, last)
+		}
+		t[last] = value
+	}
+	return json.Marshal(root)
+}
+
+// tomltable, cari atau bikin tabel bersarang dari nama bertitik (misal db atau a.b)
+// header yang sama dua kali ([db] ... [db]) ditolak kayak toml aslinya
+func tomlTable(root map[string]any, name string, header bool) (map[string]any, error) {
+	t := root
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if !bareKey(p) {
+			return nil, fmt.Errorf("nama tabel %q ga valid", name)
+		}
+		switch v := t[p].(type) {
+		case nil:
+			next := map[string]any{}
+			t[p] = next
+			t = next
+		case map[string]any:
+			if header && i == len(parts)-1 && len(v) > 0 {
+				return nil, fmt.Errorf("tabel [%s] udah ada", name)
+			}
+			t = v
+		default:
+			return nil, fmt.Errorf("%q udah dipake sebagai nilai, bukan tabel", p)
+		}
+	}
+	return t, nil
+}
+
+// tomlvalue, satu nilai: "string", 'string literal', angka bulat, atau true/false
+func tomlValue(s string) (any, error) {
+	switch {
+	case s == "":
+		return nil, fmt.Errorf("nilai kosong")
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case strings.HasPrefix(s, `"""`), strings.HasPrefix(s, "'''"):
+		return nil, fmt.Errorf("string multi-baris ga didukung")
+	case s[0] == '"':
+		v, err := strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("string ga valid: %s", s)
+		}
+		return v, nil
+	case s[0] == '\'':
+		if len(s) < 2 || !strings.HasSuffix(s, "'") || strings.Contains(s[1:len(s)-1], "'") {
+			return nil, fmt.Errorf("string ga valid: %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	case s[0] == '[', s[0] == '{':
+		return nil, fmt.Errorf("array sama inline table ga didukung")
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(s, "_", ""), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nilai %q ga didukung (cuma string, angka bulat, boolean)", s)
+	}
+	return n, nil
+}
+
+// stripcomment, buang komentar # yang ada di luar string
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++ // lewatin karakter yang di-escape
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// barekey, key toml tanpa kutip: huruf, angka, _ sama -
+func bareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fhmptrdnd/private-blog/internal/i18n"
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// cache-control per jenis halaman
+// public boleh disimpen reverse proxy, private cuma browser pemiliknya, no-store ga boleh disimpen sama sekali
+const (
+	CachePublic  = "public, max-age=300"
+	CachePrivate = "private, no-cache"
+	CacheNoStore = "no-store"
+
+	// cachearticle, halaman artikel buat pembaca biasa. pendek soalnya angka tayangan ikut ke-cache
+	cacheArticle = "public, max-age=60"
+)
+
+// withcachecontrol, pasang cache-control bawaan route, handler masih bisa nimpa
+// response error (4xx/5xx) ga boleh nyangkut di cache, jadi diganti no-store
+// response public yang ngirim cookie (misal ?theme=) diturunin jadi private biar cookie-nya ga kebagi
+func WithCacheControl(policy string) Middleware {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", policy)
+			handler(&cacheControlWriter{ResponseWriter: w}, r)
+		}
+	}
+}
+
+// cachecontrolwriter, ganti cache-control jadi no-store pas status error dikirim
+type cacheControlWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (cw *cacheControlWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		h := cw.Header()
+		switch {
+		case code >= 400:
+			h.Set("Cache-Control", CacheNoStore)
+		case len(h.Values("Set-Cookie")) > 0 && strings.HasPrefix(h.Get("Cache-Control"), "public"):
+			h.Set("Cache-Control", CachePrivate)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *cacheControlWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+func (cw *cacheControlWriter) Flush() {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// unwrap, biar http.responsecontroller bisa nembus ke writer aslinya
+func (cw *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// articleetag, etag halaman artikel dari id + updatedat, ditambah semua yang bikin tampilannya beda
+// (versi template, bahasa, tema, pemilik atau bukan)
+// views sengaja ga ikut biar tiap kunjungan ga bikin etag baru, makanya etag-nya lemah (W/)
+func articleETag(a models.Article, version string, l i18n.Locale, theme string, owner bool) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s\x00%s\x00%s\x00%t",
+		a.ID, a.UpdatedAt.UnixNano(), version, l, theme, owner)))
+	return `W/"` + hex.EncodeToString(sum[:8]) + `"`
+}
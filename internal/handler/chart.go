@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/service"
+)
+
+// ukuran grafik dalam satuan viewbox svg, di layar ikut lebar container
+const (
+	chartWidth  = 640
+	chartHeight = 220
+	chartLeft   = 40 // ruang angka sumbu y
+	chartRight  = 8
+	chartTop    = 10
+	chartBottom = 26 // ruang tanggal sumbu x
+
+	// chartmaxlabels, tanggal di sumbu x dijarangin biar ga tabrakan
+	chartMaxLabels = 6
+)
+
+// viewschart, geometri grafik batang tayangan harian
+// dihitung di server terus di-render jadi svg inline, jadi halamannya ga butuh javascript
+type viewsChart struct {
+	Width, Height int
+	Days          int
+	Bars          []chartBar
+	Grid          []chartLine
+	Labels        []chartLabel
+}
+
+// chartbar, satu batang (satu hari)
+type chartBar struct {
+	X, Y, W, H float64
+	Day        time.Time
+	Views      int
+}
+
+// chartline, garis bantu horizontal sama angkanya
+type chartLine struct {
+	Y     float64
+	Value int
+}
+
+// chartlabel, tanggal di bawah batang
+type chartLabel struct {
+	X   float64
+	Day time.Time
+}
+
+// nicestep, jarak garis bantu yang enak dibaca (1, 2, 5, 10, 20, 50, ...) minimal n
+func niceStep(n int) int {
+	step := 1
+	for {
+		for _, m := range []int{1, 2, 5} {
+			if step*m >= n {
+				return step * m
+			}
+		}
+		step *= 10
+	}
+}
+
+// newviewschart, ubah deret harian jadi batang, garis bantu, sama label
+// skala atasnya dibuletin ke kelipatan step biar garis bantunya angka bulat
+func newViewsChart(days []service.DayViews) viewsChart {
+	c := viewsChart{Width: chartWidth, Height: chartHeight, Days: len(days)}
+	if len(days) == 0 {
+		return c
+	}
+
+	max := 0
+	for _, d := range days {
+		if d.Views > max {
+			max = d.Views
+		}
+	}
+	step := niceStep((max + 3) / 4)
+	top := step * ((max + step - 1) / step)
+	if top == 0 {
+		top = step
+	}
+
+	plotW := float64(chartWidth - chartLeft - chartRight)
+	plotH := float64(chartHeight - chartTop - chartBottom)
+	baseline := float64(chartTop) + plotH
+
+	for v := 0; v <= top; v += step {
+		c.Grid = append(c.Grid, chartLine{Y: baseline - float64(v)/float64(top)*plotH, Value: v})
+	}
+
+	slot := plotW / float64(len(days))
+	every := (len(days) + chartMaxLabels - 1) / chartMaxLabels
+	for i, d := range days {
+		h := float64(d.Views) / float64(top) * plotH
+		x := float64(chartLeft) + float64(i)*slot
+		c.Bars = append(c.Bars, chartBar{X: x + slot*0.15, Y: baseline - h, W: slot * 0.7, H: h, Day: d.Day, Views: d.Views})
+		// dihitung dari belakang biar hari ini selalu dapet label
+		if (len(days)-1-i)%every == 0 {
+			c.Labels = append(c.Labels, chartLabel{X: x + slot/2, Day: d.Day})
+		}
+	}
+	return c
+}
+
+// baseline, posisi y sumbu x (garis nol)
+func (c viewsChart) Baseline() int { return chartHeight - chartBottom }
+
+// labely, posisi y tanggal di bawah sumbu x
+func (c viewsChart) LabelY() int { return chartHeight - 8 }
+
+// left, posisi x sumbu y
+func (c viewsChart) Left() int { return chartLeft }
+
+// right, ujung kanan garis bantu
+func (c viewsChart) Right() int { return chartWidth - chartRight }
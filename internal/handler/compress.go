@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultcompressminsize, body lebih kecil dari ini dikirim apa adanya
+// header gzip sama overhead-nya bikin body kecil malah ga untung
+const defaultCompressMinSize = 1024
+
+// gzipwriters, writer gzip dipake ulang, bikin baru tiap request lumayan mahal
+var gzipWriters = sync.Pool{
+	New: func() any {
+		zw, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return zw
+	},
+}
+
+// incompressible, awalan content type yang isinya udah kekompres (atau streaming)
+var incompressible = []string{
+	"image/", "video/", "audio/", "font/woff",
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-brotli",
+	"application/pdf", "application/octet-stream", "text/event-stream",
+}
+
+// compressible, cek content type layak dikompres, kosong dianggap layak (nanti di-sniff)
+func compressible(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, prefix := range incompressible {
+		if strings.HasPrefix(mediaType, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// withcompression, kompres response pake gzip kalo client mau
+// respon dinamis cuma gzip, brotli level tinggi kelamaan buat tiap request (aset statis udah punya varian brotli)
+// body ditahan dulu sampe minsize byte biar bisa mutusin, jadi body kecil ga ikut dikompres
+// taruh di luar withpanicrecovery biar halaman error dari recovery juga lewat sini
+func WithCompression(minSize int) Middleware {
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			// etag yang dikirim balik client masih ada akhiran -gzip, handler cuma kenal etag aslinya
+			if inm := r.Header.Get("If-None-Match"); strings.Contains(inm, `-gzip"`) {
+				r.Header.Set("If-None-Match", strings.ReplaceAll(inm, `-gzip"`, `"`))
+			}
+			cw := &compressWriter{
+				ResponseWriter: w,
+				minSize:        minSize,
+				accept:         r.Method != http.MethodHead && acceptsEncoding(r, "gzip"),
+			}
+			handler(cw, r)
+			// ga di-defer: kalo handler panic, sisa buffer sama penutup gzip sengaja ga dikirim
+			// biar koneksinya diputus (lihat withpanicrecovery), bukan kekirim stream yang keliatan utuh
+			cw.close()
+		}
+	}
+}
+
+// compresswriter, responsewriter yang nahan header sama awal body sampe tau perlu dikompres atau ga
+type compressWriter struct {
+	http.ResponseWriter
+	minSize int
+	accept  bool // client nerima gzip
+
+	status  int
+	buf     []byte
+	decided bool
+	zw      *gzip.Writer
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.status != 0 {
+		// header cuma dikirim sekali, sama kayak net/http
+		return
+	}
+	// 1xx dikirim langsung, ga ngabisin jatah header
+	if code >= 100 && code < 200 {
+		cw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	cw.status = code
+	h := cw.Header()
+	// yang udah bisa diputusin dari header aja ga usah nunggu body
+	switch {
+	case code == http.StatusNoContent, code == http.StatusNotModified, code == http.StatusPartialContent,
+		h.Get("Content-Encoding") != "", !compressible(h.Get("Content-Type")):
+		cw.decide(false)
+	default:
+		if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil {
+			cw.decide(n >= cw.minSize)
+		}
+	}
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.zw != nil {
+			return cw.zw.Write(b)
+		}
+		return cw.ResponseWriter.Write(b)
+	}
+	cw.buf = append(cw.buf, b...)
+	if len(cw.buf) >= cw.minSize {
+		cw.decide(true)
+	}
+	return len(b), nil
+}
+
+// decide, kirim header sama isi buffer, mulai gzip kalo compress true dan client mau
+func (cw *compressWriter) decide(compress bool) {
+	cw.decided = true
+	h := cw.Header()
+	if h.Get("Content-Type") == "" && len(cw.buf) > 0 {
+		// samain kayak net/http, biar sniff-nya ga kena body yang udah kekompres
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+	if h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		// client lain bisa dapet versi beda, cache harus tau
+		h.Add("Vary", "Accept-Encoding")
+		compress = compress && cw.accept
+		// isinya beda sama versi asli, etag kuat ga boleh sama
+		// 304 ke client yang nerima gzip juga ngomongin versi gzip
+		if compress || (cw.status == http.StatusNotModified && cw.accept) {
+			if etag := h.Get("ETag"); strings.HasPrefix(etag, `"`) {
+				h.Set("ETag", strings.TrimSuffix(etag, `"`)+`-gzip"`)
+			}
+		}
+	} else {
+		compress = false
+	}
+
+	if compress {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		cw.zw = gzipWriters.Get().(*gzip.Writer)
+		cw.zw.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.buf) > 0 {
+		if cw.zw != nil {
+			cw.zw.Write(cw.buf)
+		} else {
+			cw.ResponseWriter.Write(cw.buf)
+		}
+	}
+	cw.buf = nil
+}
+
+// close, dipanggil setelah handler selesai normal
+func (cw *compressWriter) close() {
+	if cw.status == 0 {
+		// handler ga nulis apa-apa, biar net/http yang ngurus 200 kosongnya
+		return
+	}
+	if !cw.decided {
+		// body-nya kecil, kirim apa adanya
+		cw.decide(false)
+	}
+	if cw.zw != nil {
+		cw.zw.Close()
+		cw.zw.Reset(io.Discard)
+		gzipWriters.Put(cw.zw)
+		cw.zw = nil
+	}
+}
+
+// flush, buat handler yang streaming: yang udah ditulis langsung dikirim walau belum minsize
+func (cw *compressWriter) Flush() {
+	if cw.status == 0 {
+		return
+	}
+	if !cw.decided {
+		cw.decide(true)
+	}
+	if cw.zw != nil {
+		cw.zw.Flush()
+	}
+	http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// unwrap, biar http.responsecontroller bisa nembus ke writer aslinya
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
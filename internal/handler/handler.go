@@ -3,12 +3,21 @@ package handler
 import (
 	"crypto/rand"
 	"encoding/hex"
-
-	"html/template"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/fhmptrdnd/private-blog/internal/i18n"
 	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
 	"github.com/fhmptrdnd/private-blog/internal/service"
 )
 
@@ -21,23 +30,81 @@ type Handler struct {
 	Edit       http.HandlerFunc
 	Update     http.HandlerFunc
 	Delete     http.HandlerFunc
+	Preview    http.HandlerFunc // pratinjau artikel tanpa disimpen
 	MyArticles http.HandlerFunc
+	Stats      http.HandlerFunc // statistik artikel buat pemiliknya di /stats/{id}
+	Export     http.HandlerFunc
+	Import     http.HandlerFunc
+	API        http.HandlerFunc // api kompatibel telegraph di /api/
+	Sitemap    http.HandlerFunc // /sitemap.xml sama /sitemaps/{n}.xml
+	Robots     http.HandlerFunc
+	Static     http.HandlerFunc // css, js, font di /static/
+	Healthz    http.HandlerFunc
+	Readyz     http.HandlerFunc
+}
+
+// cookieoptions, setting cookie kepemilikan artikel
+type CookieOptions struct {
+	Name   string
+	MaxAge int
+	Secure bool
+	Domain string
 }
 
+// defaultcookieoptions, setting cookie bawaan (sama kayak sebelum ada config)
+func DefaultCookieOptions() CookieOptions {
+	return CookieOptions{Name: "user_id", MaxAge: 31536000 * 10}
+}
+
+// options, setting tambahan buat newhandler
+type Options struct {
+	Cookie    CookieOptions
+	UploadDir string // tempat file upload ditampung sementara, kosong = temp dir sistem
+
+	// publicbaseurl, alamat publik buat url absolut (canonical, og:url), kosong = dari request
+	PublicBaseURL string
+
+	// robots, isi robots.txt custom, kosong = bawaan
+	Robots string
+
+	// templatedir, folder yang isinya nimpa template bawaan (path relatif sama kayak di templates/)
+	TemplateDir string
+	// templatedev, parse ulang template tiap request, buat ngedit tampilan tanpa restart
+	TemplateDev bool
+}
+
+// maximportupload, batas total ukuran request upload import
+const maxImportUpload = 32 << 20
+
+// maxarticleform, batas ukuran body form artikel (create, update, preview)
+// isi yang udah di-urlencode bisa sampe 3x lipat, sisanya buat judul, penulis, sama tema
+const maxArticleForm = 3*service.MaxContentLength + 16<<10
+
 // newhandler, bikin handler baru dengan closure
 // return handler struct yang isinya function-function
-func NewHandler(svc *service.ArticleService) Handler {
-	// parse template sekali aja biar hemat resource
-	t := template.New("templates")
-	template.Must(t.New("home").Parse(homeTemplate))
-	template.Must(t.New("view").Parse(viewTemplate))
-	template.Must(t.New("edit").Parse(editTemplate))
-	template.Must(t.New("myarticles").Parse(myArticlesTemplate))
+func NewHandler(svc *service.ArticleService, opts Options) Handler {
+	// function buat baca/bikin user id, cookie setting-nya di-capture di closure
+	getOrCreateUserID := newUserIDFunc(opts.Cookie)
+	currentUserID := newCurrentUserFunc(opts.Cookie)
+	baseURL := newBaseURLFunc(opts.PublicBaseURL)
+
+	// template dari embed (bisa ditimpa dir operator), di mode dev di-parse ulang tiap request
+	templates, err := newTemplateLoader(opts.TemplateDir, opts.TemplateDev)
+	if err != nil {
+		// main udah manggil checktemplates, jadi harusnya ga sampe sini
+		panic(err)
+	}
 
 	// helper function (closure) buat render template
-	render := func(w http.ResponseWriter, name string, data interface{}) {
-		t.ExecuteTemplate(w, name, data)
-	}
+	locale, theme := newLocaleFunc(opts.Cookie), newThemeFunc(opts.Cookie)
+	render := newRenderFunc(templates, locale, theme, "layout")
+	renderFragment := newRenderFunc(templates, locale, theme, "fragment")
+
+	// versi tampilan buat etag: template sama aset bisa beda tiap deploy, jadi etag lama ga dipake lagi
+	// di mode dev template bisa berubah kapan aja, etag dimatiin
+	started := time.Now().Truncate(time.Second)
+	renderVersion := strconv.FormatInt(started.UnixNano(), 36)
+	conditional := !opts.TemplateDev
 
 	return Handler{
 		Home: func(w http.ResponseWriter, r *http.Request) {
@@ -45,21 +112,24 @@ func NewHandler(svc *service.ArticleService) Handler {
 				http.NotFound(w, r)
 				return
 			}
-			render(w, "home", nil)
+			render(w, r, "home", nil)
 		},
 		Create: func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodPost {
 				http.Redirect(w, r, "/", http.StatusSeeOther)
 				return
 			}
+			if !parseArticleForm(w, r) {
+				return
+			}
 			title := r.FormValue("title")
 			author := r.FormValue("author")
 			content := r.FormValue("content")
 			owner := getOrCreateUserID(w, r)
 
-			a, err := svc.Create(title, author, content, owner)
+			a, err := svc.Create(title, author, content, owner, service.WithTheme(r.FormValue("theme")))
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				articleError(w, r, err)
 				return
 			}
 			http.Redirect(w, r, "/view/"+a.ID, http.StatusSeeOther)
@@ -70,18 +140,54 @@ func NewHandler(svc *service.ArticleService) Handler {
 				http.Redirect(w, r, "/", http.StatusSeeOther)
 				return
 			}
-			owner := getOrCreateUserID(w, r)
-			
-			// increment views (side effect dipisah dari query)
-			_ = svc.IncrementViews(id)
+			// cookie cuma dibaca, ga dibikin, biar halaman buat pembaca biasa aman di-cache proxy
+			owner := currentUserID(r)
 
 			a, err := svc.Get(id)
 			if err != nil {
 				http.NotFound(w, r)
 				return
 			}
-			data := viewData{Article: a, IsOwner: a.OwnerID == owner}
-			render(w, "view", data)
+			isOwner := owner != "" && a.OwnerID == owner
+
+			// catet kunjungan (side effect dipisah dari query)
+			// pemilik, bot, head, sama prefetch ga dihitung, pengunjung yang sama cuma sekali per window
+			if !isOwner && countableView(r) {
+				counted, err := svc.RecordView(id, visitorKey(r, owner), referrerDomain(r))
+				if err != nil {
+					log.Printf("record view %s: %v", id, err)
+				}
+				if counted {
+					// get di atas kejadian sebelum views-nya nambah
+					a.Views++
+				}
+			}
+
+			// pemilik liat tombol edit/hapus, jadi halamannya ga boleh nyangkut di cache bersama
+			if isOwner {
+				w.Header().Set("Cache-Control", CachePrivate)
+			} else {
+				w.Header().Set("Cache-Control", cacheArticle)
+			}
+			// ?lang= sama ?theme= nyimpen cookie, jadi harus dijawab lengkap
+			q := r.URL.Query()
+			if conditional && q.Get("lang") == "" && q.Get("theme") == "" {
+				etag := articleETag(a, renderVersion, requestLocale(r), requestTheme(r), isOwner)
+				lastModified := a.UpdatedAt
+				if started.After(lastModified) {
+					lastModified = started
+				}
+				w.Header().Set("ETag", etag)
+				w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+				if notModified(r, etag, lastModified) {
+					w.Header().Add("Vary", "Accept-Language, Cookie")
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+			}
+
+			data := viewData{Article: a, IsOwner: isOwner, Meta: articleMeta(a, baseURL(r))}
+			render(w, r, "view", data)
 		},
 		Edit: func(w http.ResponseWriter, r *http.Request) {
 			id := strings.TrimPrefix(r.URL.Path, "/edit/")
@@ -97,7 +203,7 @@ func NewHandler(svc *service.ArticleService) Handler {
 				return
 			}
 			if a.OwnerID != owner {
-				http.Error(w, "Forbidden", http.StatusForbidden)
+				http.Error(w, i18n.T(requestLocale(r), "error.forbidden"), http.StatusForbidden)
 				return
 			}
 			// prepare data untuk edit form
@@ -107,27 +213,56 @@ func NewHandler(svc *service.ArticleService) Handler {
 				Author:     a.Author,
 				Content:    a.Content,
 				ContentRaw: strings.ReplaceAll(a.Content, "<br>", "\n"),
+				Theme:      a.Theme,
 			}
-			render(w, "edit", data)
+			render(w, r, "edit", data)
 		},
 		Update: func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodPost {
 				http.Redirect(w, r, "/", http.StatusSeeOther)
 				return
 			}
+			if !parseArticleForm(w, r) {
+				return
+			}
 			id := strings.TrimPrefix(r.URL.Path, "/update/")
 			title := r.FormValue("title")
 			author := r.FormValue("author")
 			content := r.FormValue("content")
 			owner := getOrCreateUserID(w, r)
 
-			_, err := svc.Update(id, title, author, content, owner)
+			_, err := svc.Update(id, title, author, content, owner, service.WithTheme(r.FormValue("theme")))
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				articleError(w, r, err)
 				return
 			}
 			http.Redirect(w, r, "/view/"+id, http.StatusSeeOther)
 		},
+		Preview: func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Redirect(w, r, "/", http.StatusSeeOther)
+				return
+			}
+			if !parseArticleForm(w, r) {
+				return
+			}
+			// pipeline sama persis kayak create, cuma ga disimpen
+			a, err := svc.Preview(r.FormValue("title"), r.FormValue("author"), r.FormValue("content"),
+				service.WithTheme(r.FormValue("theme")))
+			if err != nil {
+				articleError(w, r, err)
+				return
+			}
+			// isinya draft, jangan sampe ke-cache di mana-mana
+			w.Header().Set("Cache-Control", "no-store")
+			data := previewData{Article: a}
+			if r.URL.Query().Get("fragment") == "1" {
+				// panel pratinjau di editor cuma butuh isi artikelnya
+				renderFragment(w, r, "preview", data)
+				return
+			}
+			render(w, r, "preview", data)
+		},
 		Delete: func(w http.ResponseWriter, r *http.Request) {
 			if r.Method != http.MethodPost {
 				http.Redirect(w, r, "/", http.StatusSeeOther)
@@ -144,34 +279,143 @@ func NewHandler(svc *service.ArticleService) Handler {
 		},
 		MyArticles: func(w http.ResponseWriter, r *http.Request) {
 			owner := getOrCreateUserID(w, r)
-			articles, err := svc.ListMyArticles(owner)
+			d, err := svc.Dashboard(owner, service.DefaultStatsDays)
 			if err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
-			data := myArticlesData{Articles: articles, Count: len(articles)}
-			render(w, "myarticles", data)
+			data := myArticlesData{Dashboard: d, Chart: newViewsChart(d.Days)}
+			render(w, r, "myarticles", data)
+		},
+		Stats: func(w http.ResponseWriter, r *http.Request) {
+			id := strings.TrimPrefix(r.URL.Path, "/stats/")
+			// cuma dibaca: yang belum punya cookie pasti bukan pemilik
+			owner := currentUserID(r)
+			if id == "" || owner == "" {
+				http.NotFound(w, r)
+				return
+			}
+			// artikel orang lain juga 404, biar ga ketauan id-nya ada
+			st, err := svc.ArticleStats(id, owner, service.DefaultStatsDays)
+			if errors.Is(err, repository.ErrNotFound) {
+				http.NotFound(w, r)
+				return
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			render(w, r, "stats", statsData{Stats: st, Chart: newViewsChart(st.Days)})
+		},
+		Export: func(w http.ResponseWriter, r *http.Request) {
+			owner := getOrCreateUserID(w, r)
+			name := "articles-" + time.Now().Format("20060102") + ".zip"
+			w.Header().Set("Content-Type", "application/zip")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+
+			// zip langsung di-stream ke response artikel per artikel, jadi header udah kekirim duluan
+			// kalo gagal di tengah jalan cuma bisa dicatat, file di client bakal rusak
+			if err := svc.ExportOwner(owner, w); err != nil {
+				log.Printf("export: %v", err)
+			}
+		},
+		Import: func(w http.ResponseWriter, r *http.Request) {
+			owner := getOrCreateUserID(w, r)
+			if r.Method == http.MethodGet {
+				render(w, r, "import", importData{})
+				return
+			}
+			if r.Method != http.MethodPost {
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+				return
+			}
+
+			// multipart dibaca part per part, file langsung ditulis ke upload dir
+			// jadi upload gede ga numpuk di memori
+			r.Body = http.MaxBytesReader(w, r.Body, maxImportUpload)
+			mr, err := r.MultipartReader()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			data := importData{Done: true}
+			for {
+				part, err := mr.NextPart()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					data.Error = err.Error()
+					break
+				}
+				if part.FormName() != "files" || part.FileName() == "" {
+					continue
+				}
+				data.Results = append(data.Results, importUpload(svc, owner, opts.UploadDir, part)...)
+			}
+			for _, res := range data.Results {
+				if res.Status == service.ImportFailed {
+					data.Failed++
+				} else {
+					data.Succeeded++
+				}
+			}
+			render(w, r, "import", data)
+		},
+		API:     NewTelegraphAPI(svc, "/api/", baseURL),
+		Sitemap: newSitemap(svc, baseURL),
+		Robots:  newRobots(opts.Robots, baseURL),
+		Static:  newStatic(staticAssets),
+		Healthz: func(w http.ResponseWriter, r *http.Request) {
+			// proses masih hidup dan bisa jawab request
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			w.Write([]byte("ok\n"))
+		},
+		Readyz: func(w http.ResponseWriter, r *http.Request) {
+			// siap kalo database bisa di-ping dan schema udah up to date
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			if err := svc.Ping(); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				fmt.Fprintf(w, "not ready: %v\n", err)
+				return
+			}
+			w.Write([]byte("ok\n"))
 		},
 	}
 }
 
-// getorcreateuserid, dapetin id user dari cookie, kalo ga ada bikin baru
-func getOrCreateUserID(w http.ResponseWriter, r *http.Request) string {
-	cookie, err := r.Cookie("user_id")
-	if err == nil && cookie.Value != "" {
-		return cookie.Value
+// newuseridfunc, bikin function yang dapetin id user dari cookie, kalo ga ada bikin baru
+// setting cookie di-capture di closure
+func newUserIDFunc(c CookieOptions) func(http.ResponseWriter, *http.Request) string {
+	return func(w http.ResponseWriter, r *http.Request) string {
+		cookie, err := r.Cookie(c.Name)
+		if err == nil && cookie.Value != "" {
+			return cookie.Value
+		}
+		// bikin baru kalo ga nemu
+		id := generateID()
+		http.SetCookie(w, &http.Cookie{
+			Name:     c.Name,
+			Value:    id,
+			Path:     "/",
+			Domain:   c.Domain,
+			MaxAge:   c.MaxAge,
+			Secure:   c.Secure,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		return id
+	}
+}
+
+// newcurrentuserfunc, baca id user dari cookie tanpa bikin baru, kosong kalo belum punya
+func newCurrentUserFunc(c CookieOptions) func(*http.Request) string {
+	return func(r *http.Request) string {
+		if cookie, err := r.Cookie(c.Name); err == nil {
+			return cookie.Value
+		}
+		return ""
 	}
-	// bikin baru kalo ga nemu
-	id := generateID()
-	http.SetCookie(w, &http.Cookie{
-		Name:     "user_id",
-		Value:    id,
-		Path:     "/",
-		MaxAge:   31536000 * 10,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	})
-	return id
 }
 
 // generateid, bikin id random (hex string)
@@ -181,656 +425,128 @@ func generateID() string {
 	return hex.EncodeToString(b)
 }
 
-// templates html (langsung di sini biar gampang, ga perlu file html terpisah)
-const homeTemplate = `
-<!DOCTYPE html>
-<html lang="id">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Telegraph Clone</title>
-    <style>
-        * {
-            margin: 0;
-            padding: 0;
-            box-sizing: border-box;
-        }
-
-        body {
-            font-family: 'Georgia', serif;
-            background: #f7f7f7;
-            color: #333;
-            line-height: 1.6;
-        }
-
-        .header {
-            background: white;
-            border-bottom: 1px solid #e0e0e0;
-            padding: 20px 0;
-            position: sticky;
-            top: 0;
-            z-index: 100;
-        }
-
-        .container {
-            max-width: 720px;
-            margin: 0 auto;
-            padding: 0 20px;
-        }
-
-        .logo {
-            font-size: 1.8em;
-            font-weight: bold;
-            color: #333;
-            text-decoration: none;
-        }
-
-        .editor {
-            background: white;
-            margin: 40px auto;
-            padding: 60px 80px;
-            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
-        }
-
-        input[type="text"] {
-            width: 100%;
-            border: none;
-            font-size: 2.5em;
-            font-family: 'Georgia', serif;
-            margin-bottom: 20px;
-            outline: none;
-        }
-
-        input[type="text"]::placeholder {
-            color: #ccc;
-        }
-
-        .author-input {
-            font-size: 1.1em !important;
-            margin-bottom: 30px;
-        }
-
-        textarea {
-            width: 100%;
-            min-height: 400px;
-            border: none;
-            font-size: 1.2em;
-            font-family: 'Georgia', serif;
-            line-height: 1.8;
-            resize: vertical;
-            outline: none;
-        }
-
-        textarea::placeholder {
-            color: #ccc;
-        }
-
-        .btn {
-            background: #333;
-            color: white;
-            border: none;
-            padding: 12px 30px;
-            font-size: 16px;
-            cursor: pointer;
-            border-radius: 4px;
-            transition: background 0.3s;
-        }
-
-        .btn:hover {
-            background: #555;
-        }
-
-        .btn-container {
-            text-align: right;
-            margin-top: 20px;
-        }
-
-        .footer {
-            text-align: center;
-            padding: 40px 20px;
-            color: #999;
-            font-size: 0.9em;
-        }
-
-        @media (max-width: 768px) {
-            .editor {
-                padding: 40px 20px;
-            }
-
-            input[type="text"] {
-                font-size: 1.8em;
-            }
-
-            textarea {
-                font-size: 1.1em;
-            }
-        }
-    </style>
-</head>
-<body>
-    <div class="header">
-        <div class="container">
-            <a href="/" class="logo">Telegraph</a>
-        </div>
-    </div>
-
-    <div class="container">
-        <div class="editor">
-            <form method="POST" action="/create">
-                <input type="text" name="title" placeholder="Judul" required>
-                <input type="text" name="author" class="author-input" placeholder="Nama Penulis" required>
-                <textarea name="content" placeholder="Ceritakan kisahmu..." required></textarea>
-                
-                <div class="btn-container">
-                    <button type="submit" class="btn">Publikasikan</button>
-                </div>
-            </form>
-        </div>
-    </div>
-
-    <div class="footer">
-        Telegraph Clone - Buat artikel dengan mudah
-    </div>
-</body>
-</html>
-`
-
-const viewTemplate = `
-<!DOCTYPE html>
-<html lang="id">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>{{.Article.Title}}</title>
-    <style>
-        * {
-            margin: 0;
-            padding: 0;
-            box-sizing: border-box;
-        }
-
-        body {
-            font-family: 'Georgia', serif;
-            background: #f7f7f7;
-            color: #333;
-            line-height: 1.6;
-        }
-
-        .header {
-            background: white;
-            border-bottom: 1px solid #e0e0e0;
-            padding: 20px 0;
-        }
-
-        .container {
-            max-width: 720px;
-            margin: 0 auto;
-            padding: 0 20px;
-        }
-
-        .logo {
-            font-size: 1.8em;
-            font-weight: bold;
-            color: #333;
-            text-decoration: none;
-        }
-
-        article {
-            background: white;
-            margin: 40px auto;
-            padding: 60px 80px;
-            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
-        }
-
-        h1 {
-            font-size: 2.5em;
-            margin-bottom: 20px;
-            line-height: 1.2;
-        }
-
-        .meta {
-            color: #999;
-            font-size: 0.95em;
-            margin-bottom: 40px;
-            padding-bottom: 20px;
-            border-bottom: 1px solid #f0f0f0;
-        }
-
-        .content {
-            font-size: 1.2em;
-            line-height: 1.8;
-        }
-
-        .content p {
-            margin-bottom: 1em;
-        }
-
-        .stats {
-            margin-top: 40px;
-            padding-top: 20px;
-            border-top: 1px solid #f0f0f0;
-            color: #999;
-            font-size: 0.9em;
-        }
-
-        .btn-home {
-            display: inline-block;
-            margin-top: 20px;
-            padding: 10px 20px;
-            background: #333;
-            color: white;
-            text-decoration: none;
-            border-radius: 4px;
-            transition: background 0.3s;
-        }
-
-        .btn-home:hover {
-            background: #555;
-        }
-
-        .owner-actions {
-            margin-top: 30px;
-            padding-top: 20px;
-            border-top: 1px solid #f0f0f0;
-            display: flex;
-            gap: 10px;
-        }
-
-        .btn-edit {
-            background: #4CAF50;
-            color: white;
-            padding: 10px 20px;
-            text-decoration: none;
-            border-radius: 4px;
-            transition: background 0.3s;
-            display: inline-block;
-        }
-
-        .btn-edit:hover {
-            background: #45a049;
-        }
-
-        .btn-delete {
-            background: #f44336;
-            color: white;
-            border: none;
-            padding: 10px 20px;
-            border-radius: 4px;
-            cursor: pointer;
-            transition: background 0.3s;
-        }
-
-        .btn-delete:hover {
-            background: #da190b;
-        }
-
-        @media (max-width: 768px) {
-            article {
-                padding: 40px 20px;
-            }
-
-            h1 {
-                font-size: 1.8em;
-            }
-
-            .content {
-                font-size: 1.1em;
-            }
-
-            .owner-actions {
-                flex-direction: column;
-            }
-        }
-    </style>
-</head>
-<body>
-    <div class="header">
-        <div class="container">
-            <a href="/" class="logo">Telegraph</a>
-        </div>
-    </div>
-
-    <div class="container">
-        <article>
-            <h1>{{.Article.Title}}</h1>
-            
-            <div class="meta">
-                Oleh <strong>{{.Article.Author}}</strong> · {{.Article.CreatedAt.Format "2 January 2006"}}
-            </div>
-
-            <div class="content">
-                {{.Article.Content}}
-            </div>
-
-            <div class="stats">
-                {{.Article.Views}} tayangan
-            </div>
-
-            {{if .IsOwner}}
-            <div class="owner-actions">
-                <a href="/edit/{{.Article.ID}}" class="btn-edit">Edit Artikel</a>
-                <form method="POST" action="/delete/{{.Article.ID}}" style="display: inline;" onsubmit="return confirm('Yakin ingin menghapus artikel ini?');">
-                    <button type="submit" class="btn-delete">Hapus Artikel</button>
-                </form>
-            </div>
-            {{end}}
-
-            <a href="/" class="btn-home">Buat Artikel Baru</a>
-        </article>
-    </div>
-</body>
-</html>
-`
-
-const editTemplate = `
-<!DOCTYPE html>
-<html lang="id">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Edit - {{.Title}}</title>
-    <style>
-        * {
-            margin: 0;
-            padding: 0;
-            box-sizing: border-box;
-        }
-
-        body {
-            font-family: 'Georgia', serif;
-            background: #f7f7f7;
-            color: #333;
-            line-height: 1.6;
-        }
-
-        .header {
-            background: white;
-            border-bottom: 1px solid #e0e0e0;
-            padding: 20px 0;
-        }
-
-        .container {
-            max-width: 720px;
-            margin: 0 auto;
-            padding: 0 20px;
-        }
-
-        .logo {
-            font-size: 1.8em;
-            font-weight: bold;
-            color: #333;
-            text-decoration: none;
-        }
-
-        .editor {
-            background: white;
-            margin: 40px auto;
-            padding: 60px 80px;
-            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
-        }
-
-        .edit-label {
-            color: #4CAF50;
-            font-size: 0.9em;
-            margin-bottom: 20px;
-            display: block;
-        }
-
-        input[type="text"] {
-            width: 100%;
-            border: none;
-            font-size: 2.5em;
-            font-family: 'Georgia', serif;
-            margin-bottom: 20px;
-            outline: none;
-        }
-
-        .author-input {
-            font-size: 1.1em !important;
-            margin-bottom: 30px;
-        }
-
-        textarea {
-            width: 100%;
-            min-height: 400px;
-            border: none;
-            font-size: 1.2em;
-            font-family: 'Georgia', serif;
-            line-height: 1.8;
-            resize: vertical;
-            outline: none;
-        }
-
-        .btn {
-            background: #333;
-            color: white;
-            border: none;
-            padding: 12px 30px;
-            font-size: 16px;
-            cursor: pointer;
-            border-radius: 4px;
-            transition: background 0.3s;
-            margin-right: 10px;
-        }
-
-        .btn:hover {
-            background: #555;
-        }
-
-        .btn-cancel {
-            background: #999;
-        }
-
-        .btn-cancel:hover {
-            background: #777;
-        }
-
-        .btn-container {
-            text-align: right;
-            margin-top: 20px;
-        }
-
-        @media (max-width: 768px) {
-            .editor {
-                padding: 40px 20px;
-            }
-
-            input[type="text"] {
-                font-size: 1.8em;
-            }
-
-            textarea {
-                font-size: 1.1em;
-            }
-        }
-    </style>
-</head>
-<body>
-    <div class="header">
-        <div class="container">
-            <a href="/" class="logo">Telegraph</a>
-        </div>
-    </div>
-
-    <div class="container">
-        <div class="editor">
-            <span class="edit-label">✏️ Mode Edit</span>
-            <form method="POST" action="/update/{{.ID}}" onsubmit="return confirm('Simpan perubahan artikel ini?');">
-                <input type="text" name="title" value="{{.Title}}" required>
-                <input type="text" name="author" class="author-input" value="{{.Author}}" required>
-                <textarea name="content" required>{{.ContentRaw}}</textarea>
-                
-                <div class="btn-container">
-                    <a href="/view/{{.ID}}" class="btn btn-cancel">Batal</a>
-                    <button type="submit" class="btn">Simpan Perubahan</button>
-                </div>
-            </form>
-        </div>
-    </div>
-</body>
-</html>
-`
+// validuserid, cek string formatnya sama kayak hasil generateid (16 huruf hex kecil)
+func validUserID(s string) bool {
+	if len(s) != 16 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
 
 type viewData struct {
 	Article models.Article
 	IsOwner bool
+	Meta    pageMeta
+}
+
+// themehint, halaman artikel nyaranin tema pilihan penulisnya
+func (d viewData) themeHint() string { return d.Article.Theme }
+
+// previewdata, artikel hasil pipeline yang belum disimpen
+type previewData struct {
+	Article models.Article
 }
 
+// themehint, pratinjau pake tema yang dipilih di form
+func (d previewData) themeHint() string { return d.Article.Theme }
+
 type editData struct {
 	ID         string
 	Title      string
 	Author     string
 	Content    string
 	ContentRaw string
+	Theme      string
 }
 
 type myArticlesData struct {
-	Articles []models.Article
-	Count    int
+	Dashboard service.Dashboard
+	Chart     viewsChart
+}
+
+type statsData struct {
+	Stats service.ArticleStats
+	Chart viewsChart
+}
+
+type importData struct {
+	Done      bool
+	Results   []service.ImportResult
+	Succeeded int
+	Failed    int
+	Error     string
+}
+
+// parsearticleform, baca form artikel dengan batas ukuran body
+// false kalo gagal, response error-nya udah dikirim
+func parseArticleForm(w http.ResponseWriter, r *http.Request) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxArticleForm)
+	if err := r.ParseForm(); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			http.Error(w, i18n.T(requestLocale(r), "error.too_large"), http.StatusRequestEntityTooLarge)
+			return false
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// articleerror, input yang ga lolos validasi jadi 400, sisanya 500
+// pesannya diterjemahin ke bahasa pembaca, error internal cuma masuk log
+func articleError(w http.ResponseWriter, r *http.Request, err error) {
+	l := requestLocale(r)
+	var invalid *service.ValidationError
+	if errors.As(err, &invalid) {
+		http.Error(w, i18n.T(l, invalid.Key, invalid.Args...), http.StatusBadRequest)
+		return
+	}
+	log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	http.Error(w, i18n.T(l, "error.internal"), http.StatusInternalServerError)
+}
+
+// importupload, simpen satu file upload ke upload dir terus import isinya
+// zip butuh random access, makanya ditampung ke file dulu, bukan dibaca langsung dari request
+func importUpload(svc *service.ArticleService, owner, dir string, part *multipart.Part) []service.ImportResult {
+	name := filepath.Base(part.FileName())
+	failed := func(err error) []service.ImportResult {
+		return []service.ImportResult{{File: name, Status: service.ImportFailed, Error: err.Error()}}
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext != ".zip" && ext != ".md" && ext != ".markdown" {
+		return failed(errors.New("cuma bisa .zip, .md, atau .markdown"))
+	}
+
+	f, err := os.CreateTemp(dir, "import-*"+ext)
+	if err != nil {
+		return failed(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size, err := io.Copy(f, part)
+	if err != nil {
+		return failed(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return failed(err)
+	}
+
+	src := service.FileSource(name, f)
+	if ext == ".zip" {
+		src = service.ZipSource(f, size)
+	}
+	results, err := svc.Import(owner, src)
+	if err != nil {
+		return append(results, failed(err)...)
+	}
+	return results
 }
 
-const myArticlesTemplate = `
-<!DOCTYPE html>
-<html lang="id">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Artikel Saya</title>
-    <style>
-        * {
-            margin: 0;
-            padding: 0;
-            box-sizing: border-box;
-        }
-
-        body {
-            font-family: 'Georgia', serif;
-            background: #f7f7f7;
-            color: #333;
-            line-height: 1.6;
-        }
-
-        .header {
-            background: white;
-            border-bottom: 1px solid #e0e0e0;
-            padding: 20px 0;
-        }
-
-        .container {
-            max-width: 720px;
-            margin: 0 auto;
-            padding: 0 20px;
-        }
-
-        .logo {
-            font-size: 1.8em;
-            font-weight: bold;
-            color: #333;
-            text-decoration: none;
-        }
-
-        .page-title {
-            margin: 40px 0 20px;
-            font-size: 2em;
-        }
-
-        .article-count {
-            color: #999;
-            margin-bottom: 30px;
-        }
-
-        .article-list {
-            list-style: none;
-        }
-
-        .article-item {
-            background: white;
-            padding: 20px;
-            margin-bottom: 15px;
-            box-shadow: 0 1px 3px rgba(0,0,0,0.1);
-            border-radius: 4px;
-            transition: transform 0.2s;
-        }
-
-        .article-item:hover {
-            transform: translateY(-2px);
-            box-shadow: 0 3px 6px rgba(0,0,0,0.15);
-        }
-
-        .article-title {
-            font-size: 1.4em;
-            margin-bottom: 10px;
-        }
-
-        .article-title a {
-            color: #333;
-            text-decoration: none;
-        }
-
-        .article-title a:hover {
-            color: #4CAF50;
-        }
-
-        .article-meta {
-            color: #999;
-            font-size: 0.9em;
-        }
-
-        .empty-state {
-            text-align: center;
-            padding: 60px 20px;
-            color: #999;
-        }
-
-        .btn-home {
-            display: inline-block;
-            margin-top: 30px;
-            padding: 12px 30px;
-            background: #333;
-            color: white;
-            text-decoration: none;
-            border-radius: 4px;
-            transition: background 0.3s;
-        }
-
-        .btn-home:hover {
-            background: #555;
-        }
-    </style>
-</head>
-<body>
-    <div class="header">
-        <div class="container">
-            <a href="/" class="logo">Telegraph</a>
-        </div>
-    </div>
-
-    <div class="container">
-        <h1 class="page-title">Artikel Saya</h1>
-        <p class="article-count">{{.Count}} artikel</p>
-
-        {{if .Articles}}
-        <ul class="article-list">
-            {{range .Articles}}
-            <li class="article-item">
-                <h2 class="article-title">
-                    <a href="/view/{{.ID}}">{{.Title}}</a>
-                </h2>
-                <div class="article-meta">
-                    Oleh {{.Author}} · {{.CreatedAt.Format "2 Jan 2006"}} · 👁️ {{.Views}} views
-                </div>
-            </li>
-            {{end}}
-        </ul>
-        {{else}}
-        <div class="empty-state">
-            <p>Belum ada artikel.</p>
-            <p>Mulai menulis artikel pertamamu!</p>
-        </div>
-        {{end}}
-
-        <a href="/" class="btn-home">Buat Artikel Baru</a>
-    </div>
-</body>
-</html>
-`
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fhmptrdnd/private-blog/internal/i18n"
+)
+
+// localecookie, nama cookie pilihan bahasa
+const localeCookie = "lang"
+
+// newlocalefunc, bikin function yang nentuin bahasa halaman
+// urutan: ?lang= di url (sekalian disimpen ke cookie), cookie, accept-language, default
+func newLocaleFunc(c CookieOptions) func(http.ResponseWriter, *http.Request) i18n.Locale {
+	return func(w http.ResponseWriter, r *http.Request) i18n.Locale {
+		// halaman bisa beda tergantung header ini, cache harus tau
+		w.Header().Add("Vary", "Accept-Language, Cookie")
+
+		if l, ok := i18n.Parse(r.URL.Query().Get("lang")); ok {
+			http.SetCookie(w, &http.Cookie{
+				Name:     localeCookie,
+				Value:    string(l),
+				Path:     "/",
+				Domain:   c.Domain,
+				MaxAge:   c.MaxAge,
+				Secure:   c.Secure,
+				HttpOnly: true,
+				SameSite: http.SameSiteLaxMode,
+			})
+		}
+		return requestLocale(r)
+	}
+}
+
+// requestlocale, bahasa yang bakal dipake buat request ini, tanpa nyentuh response
+// dipake juga buat ngitung etag sebelum halaman di-render
+func requestLocale(r *http.Request) i18n.Locale {
+	if l, ok := i18n.Parse(r.URL.Query().Get("lang")); ok {
+		return l
+	}
+	if cookie, err := r.Cookie(localeCookie); err == nil {
+		if l, ok := i18n.Parse(cookie.Value); ok {
+			return l
+		}
+	}
+	return i18n.Negotiate(r.Header.Get("Accept-Language"))
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// sitename, nama situs di og:site_name dan json-ld
+const siteName = "Telegraph"
+
+// newbaseurlfunc, bikin function yang balikin alamat publik situs tanpa "/" di belakang
+// kalo publicbaseurl diisi selalu pake itu, kalo kosong ditebak dari request
+func newBaseURLFunc(public string) func(*http.Request) string {
+	public = strings.TrimRight(public, "/")
+	return func(r *http.Request) string {
+		if public != "" {
+			return public
+		}
+		scheme := "http"
+		if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+			scheme = "https"
+		}
+		return scheme + "://" + r.Host
+	}
+}
+
+// imagemarkup, gambar pertama di content: ![alt](src)
+var imageMarkup = regexp.MustCompile(`!\[[^\]]*\]\(([^)\s]+)\)`)
+
+// firstimage, url gambar pertama di artikel (absolut), kosong kalo ga ada
+func firstImage(content, base string) string {
+	m := imageMarkup.FindStringSubmatch(content)
+	if m == nil {
+		return ""
+	}
+	src := m[1]
+	if strings.HasPrefix(src, "/") && !strings.HasPrefix(src, "//") {
+		return base + src
+	}
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		return src
+	}
+	return ""
+}
+
+// pagemeta, isi tag opengraph, twitter card, sama json-ld halaman artikel
+type pageMeta struct {
+	SiteName    string
+	URL         string
+	Title       string
+	Description string
+	Author      string
+	Image       string
+	Published   string
+	Modified    string
+	TwitterCard string
+	JSONLD      template.JS
+}
+
+// jsonldperson, author di schema.org
+type jsonLDPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// jsonldarticle, schema.org article
+type jsonLDArticle struct {
+	Context          string            `json:"@context"`
+	Type             string            `json:"@type"`
+	Headline         string            `json:"headline"`
+	Description      string            `json:"description,omitempty"`
+	Author           *jsonLDPerson     `json:"author,omitempty"`
+	Publisher        jsonLDPerson      `json:"publisher"`
+	DatePublished    string            `json:"datePublished"`
+	DateModified     string            `json:"dateModified"`
+	URL              string            `json:"url"`
+	MainEntityOfPage map[string]string `json:"mainEntityOfPage"`
+	Image            []string          `json:"image,omitempty"`
+	WordCount        int               `json:"wordCount,omitempty"`
+}
+
+// articlemeta, hitung semua metadata halaman dari artikel
+// pure function: cuma butuh artikel sama base url
+func articleMeta(a models.Article, base string) pageMeta {
+	m := pageMeta{
+		SiteName:    siteName,
+		URL:         base + "/view/" + a.ID,
+		Title:       a.Title,
+		Description: a.Excerpt,
+		Author:      a.Author,
+		Image:       firstImage(a.Content, base),
+		Published:   a.CreatedAt.UTC().Format(time.RFC3339),
+		Modified:    a.UpdatedAt.UTC().Format(time.RFC3339),
+		TwitterCard: "summary",
+	}
+	if m.Description == "" {
+		m.Description = a.Title
+	}
+	if m.Image != "" {
+		m.TwitterCard = "summary_large_image"
+	}
+
+	// google motong headline di 110 huruf
+	headline := []rune(a.Title)
+	if len(headline) > 110 {
+		headline = headline[:110]
+	}
+	ld := jsonLDArticle{
+		Context:          "https://schema.org",
+		Type:             "Article",
+		Headline:         string(headline),
+		Description:      m.Description,
+		Publisher:        jsonLDPerson{Type: "Organization", Name: siteName},
+		DatePublished:    m.Published,
+		DateModified:     m.Modified,
+		URL:              m.URL,
+		MainEntityOfPage: map[string]string{"@type": "WebPage", "@id": m.URL},
+		WordCount:        a.WordCount,
+	}
+	if a.Author != "" {
+		ld.Author = &jsonLDPerson{Type: "Person", Name: a.Author}
+	}
+	if m.Image != "" {
+		ld.Image = []string{m.Image}
+	}
+	// json.marshal udah escape <, >, & jadi \u003c dst, aman ditaruh di dalam <script>
+	b, _ := json.Marshal(ld)
+	m.JSONLD = template.JS(b)
+	return m
+}
@@ -1,8 +1,16 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/metrics"
 )
 
 // middleware, function yang nerima function dan return function
@@ -18,6 +26,169 @@ func WithLogging(handler http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// newlogging, pilih middleware logging sesuai format ("text" atau "json")
+func NewLogging(format string) Middleware {
+	if format != "json" {
+		return WithLogging
+	}
+	enc := json.NewEncoder(os.Stdout)
+	var mu sync.Mutex // encoder ga aman dipake barengan
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			enc.Encode(map[string]string{
+				"time":   time.Now().Format(time.RFC3339),
+				"level":  "info",
+				"method": r.Method,
+				"path":   r.URL.Path,
+			})
+			mu.Unlock()
+			handler(w, r)
+		}
+	}
+}
+
+// withratelimit, batasin jumlah request per ip pake token bucket
+// state bucket disimpan di closure, jadi middleware ini harus dibikin sekali terus dipake bareng
+func WithRateLimit(perMinute, burst int) Middleware {
+	// 0 artinya ga dibatasin, balikin middleware yang ga ngapa-ngapain
+	if perMinute <= 0 {
+		return func(handler http.HandlerFunc) http.HandlerFunc { return handler }
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	type bucket struct {
+		tokens float64
+		last   time.Time
+	}
+	var mu sync.Mutex
+	buckets := map[string]*bucket{}
+	refill := float64(perMinute) / float64(time.Minute)
+
+	// allow, ambil satu token dari bucket ip, false kalo udah habis
+	allow := func(ip string, now time.Time) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// bersihin bucket yang udah lama ga dipake biar map ga bengkak
+		if len(buckets) > 10000 {
+			for k, b := range buckets {
+				if now.Sub(b.last) > time.Minute {
+					delete(buckets, k)
+				}
+			}
+		}
+
+		b, ok := buckets[ip]
+		if !ok {
+			b = &bucket{tokens: float64(burst), last: now}
+			buckets[ip] = b
+		}
+		b.tokens += float64(now.Sub(b.last)) * refill
+		if b.tokens > float64(burst) {
+			b.tokens = float64(burst)
+		}
+		b.last = now
+		if b.tokens < 1 {
+			return false
+		}
+		b.tokens--
+		return true
+	}
+
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !allow(clientIP(r), time.Now()) {
+				w.Header().Set("Retry-After", "60")
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			handler(w, r)
+		}
+	}
+}
+
+// clientip, ambil ip dari remoteaddr (tanpa port)
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusrecorder, responsewriter yang nyatet status code yang dikirim
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(code int) {
+	if rec.status == 0 {
+		rec.status = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(b)
+}
+
+// flush, biar handler yang streaming tetep bisa flush walau dibungkus
+func (rec *statusRecorder) Flush() {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	http.NewResponseController(rec.ResponseWriter).Flush()
+}
+
+// unwrap, biar http.responsecontroller bisa nembus ke writer aslinya
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
+
+// httpmetrics, metric request yang dicatet withmetrics
+type HTTPMetrics struct {
+	Requests *metrics.CounterVec
+	Duration *metrics.HistogramVec
+}
+
+// newhttpmetrics, daftarin metric request ke registry
+func NewHTTPMetrics(reg *metrics.Registry) HTTPMetrics {
+	return HTTPMetrics{
+		Requests: reg.Counter("http_requests_total", "Total HTTP requests by route and status.", "route", "status"),
+		Duration: reg.Histogram("http_request_duration_seconds", "HTTP request latency by route and status.",
+			metrics.DefaultBuckets, "route", "status"),
+	}
+}
+
+// withmetrics, catet jumlah request sama latency per route dan status
+// route dipake sebagai label (bukan path asli) biar label ga meledak
+func WithMetrics(m HTTPMetrics, route string) Middleware {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+			// defer biar request yang panic tetep kecatet
+			defer func() {
+				status := rec.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				code := strconv.Itoa(status)
+				m.Requests.Inc(route, code)
+				m.Duration.Observe(time.Since(start).Seconds(), route, code)
+			}()
+			handler(rec, r)
+		}
+	}
+}
+
 // withmethodcheck, cek method http yang diizinkan
 // ini contoh closure: function capture variable allowedmethod
 func WithMethodCheck(allowedMethod string) Middleware {
@@ -33,15 +204,31 @@ func WithMethodCheck(allowedMethod string) Middleware {
 }
 
 // withpanicrecovery, tangkep panic biar ga crash
+// kalo response udah mulai dikirim, halaman error ga bisa ditempel lagi (bakal nyampur sama body,
+// apalagi kalo body-nya gzip), jadi koneksinya diputus aja pake http.erraborthandler
 func WithPanicRecovery(handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
 		defer func() {
-			if err := recover(); err != nil {
-				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-				fmt.Printf("[PANIC] %v\n", err)
+			err := recover()
+			if err == nil {
+				return
 			}
+			if err == http.ErrAbortHandler {
+				panic(err)
+			}
+			fmt.Printf("[PANIC] %v\n", err)
+			if rec.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+			// header yang udah disiapin handler ga berlaku buat halaman error
+			h := w.Header()
+			for _, k := range []string{"Content-Encoding", "Content-Length", "ETag", "Last-Modified", "Cache-Control"} {
+				h.Del(k)
+			}
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}()
-		handler(w, r)
+		handler(rec, r)
 	}
 }
 
@@ -61,7 +248,9 @@ func Chain(handler http.HandlerFunc, middlewares ...Middleware) http.HandlerFunc
 }
 
 // withusercontext, bikin closure yang capture user id handling
-func WithUserContext(handler func(w http.ResponseWriter, r *http.Request, userID string)) http.HandlerFunc {
+// setting cookie harus dikasih dari luar biar sama kayak yang dipake handler (nama, domain, secure)
+func WithUserContext(c CookieOptions, handler func(w http.ResponseWriter, r *http.Request, userID string)) http.HandlerFunc {
+	getOrCreateUserID := newUserIDFunc(c)
 	return func(w http.ResponseWriter, r *http.Request) {
 		userID := getOrCreateUserID(w, r) // Captured in closure
 		handler(w, r, userID)
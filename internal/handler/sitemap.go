@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+	"github.com/fhmptrdnd/private-blog/internal/service"
+)
+
+const (
+	// maxsitemapurls, batas url per file sitemap dari sitemaps.org
+	maxSitemapURLs = 50000
+	// sitemapmaxage, berapa detik sitemap boleh di-cache sebelum dicek lagi
+	sitemapMaxAge = 300
+)
+
+// defaultrobots, robots.txt bawaan: halaman milik user sama api ga usah di-crawl
+// baris sitemap ditambahin pas request karena butuh base url
+const defaultRobots = `User-agent: *
+Disallow: /my-articles
+Disallow: /edit/
+Disallow: /update/
+Disallow: /delete/
+Disallow: /stats/
+Disallow: /create
+Disallow: /api/
+`
+
+// sitemapetag, etag sitemap dari jumlah artikel, waktu update terakhir, sama nomor halaman
+// artikel baru, diedit, atau dihapus pasti ngubah salah satunya
+func sitemapETag(st repository.PublicStats, page int) string {
+	return fmt.Sprintf(`"%d-%d-%d"`, st.Count, st.LastUpdated.UnixNano(), page)
+}
+
+// notmodified, cek if-none-match / if-modified-since, if-none-match yang menang kalo ada dua-duanya
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			// perbandingan lemah, W/ di dua sisi diabaikan
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !lastModified.Truncate(time.Second).After(since)
+}
+
+// writexmltext, tulis teks yang udah di-escape buat xml
+func writeXMLText(w io.Writer, s string) {
+	xml.EscapeText(w, []byte(s))
+}
+
+// sitemaplastmod, format tanggal w3c yang diminta sitemap
+func sitemapLastmod(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// newsitemap, handler /sitemap.xml sama /sitemaps/{n}.xml
+// kalo artikelnya lebih dari 50.000, /sitemap.xml jadi sitemap index yang nunjuk ke /sitemaps/{n}.xml
+// isinya di-stream langsung dari repository, ga ditampung di memori
+func newSitemap(svc *service.ArticleService, baseURL func(*http.Request) string) http.HandlerFunc {
+
+	// writeurlset, satu file sitemap isi artikel dari offset sampai offset+limit
+	writeURLSet := func(w *bufio.Writer, base string, offset, limit int) error {
+		io.WriteString(w, xml.Header)
+		io.WriteString(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+"\n")
+		err := svc.EachPublic(offset, limit, func(a models.Article) error {
+			io.WriteString(w, "<url><loc>")
+			writeXMLText(w, base+"/view/"+a.ID)
+			io.WriteString(w, "</loc><lastmod>"+sitemapLastmod(a.UpdatedAt)+"</lastmod></url>\n")
+			return nil
+		})
+		io.WriteString(w, "</urlset>\n")
+		return err
+	}
+
+	// writeindex, sitemap index yang nunjuk ke tiap potongan
+	// lastmod per potongan ga diitung satu-satu, pake update terakhir keseluruhan
+	writeIndex := func(w *bufio.Writer, base string, pages int, lastmod time.Time) {
+		io.WriteString(w, xml.Header)
+		io.WriteString(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">`+"\n")
+		for n := 1; n <= pages; n++ {
+			io.WriteString(w, "<sitemap><loc>")
+			writeXMLText(w, base+"/sitemaps/"+strconv.Itoa(n)+".xml")
+			io.WriteString(w, "</loc><lastmod>"+sitemapLastmod(lastmod)+"</lastmod></sitemap>\n")
+		}
+		io.WriteString(w, "</sitemapindex>\n")
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// page 0 = /sitemap.xml, page n = /sitemaps/n.xml
+		page := 0
+		if r.URL.Path != "/sitemap.xml" {
+			name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/sitemaps/"), ".xml")
+			n, err := strconv.Atoi(name)
+			if !ok || err != nil || n < 1 || strconv.Itoa(n) != name {
+				http.NotFound(w, r)
+				return
+			}
+			page = n
+		}
+
+		st, err := svc.PublicStats()
+		if err != nil {
+			log.Printf("sitemap: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		pages := (st.Count + maxSitemapURLs - 1) / maxSitemapURLs
+		if page > 0 && page > max(pages, 1) {
+			http.NotFound(w, r)
+			return
+		}
+
+		etag := sitemapETag(st, page)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(sitemapMaxAge))
+		if !st.LastUpdated.IsZero() {
+			w.Header().Set("Last-Modified", st.LastUpdated.UTC().Format(http.TimeFormat))
+		}
+		if notModified(r, etag, st.LastUpdated) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		bw := bufio.NewWriter(w)
+		base := baseURL(r)
+		switch {
+		case page == 0 && pages > 1:
+			writeIndex(bw, base, pages, st.LastUpdated)
+		case page == 0:
+			err = writeURLSet(bw, base, 0, maxSitemapURLs)
+		default:
+			err = writeURLSet(bw, base, (page-1)*maxSitemapURLs, maxSitemapURLs)
+		}
+		// header udah kekirim, error di tengah jalan cuma bisa dicatet
+		if err != nil {
+			log.Printf("sitemap: %v", err)
+		}
+		if err := bw.Flush(); err != nil {
+			log.Printf("sitemap: %v", err)
+		}
+	}
+}
+
+// newrobots, handler /robots.txt
+// kalo custom kosong pake bawaan plus baris sitemap, kalo diisi dikirim apa adanya
+func newRobots(custom string, baseURL func(*http.Request) string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body := custom
+		if body == "" {
+			body = defaultRobots + "\nSitemap: " + baseURL(r) + "/sitemap.xml\n"
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "public, max-age=3600")
+		io.WriteString(w, body)
+	}
+}
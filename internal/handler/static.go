@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// embeddedstatic, css, js, sama font yang ikut ke-compile ke binary
+//
+//go:embed static
+var embeddedStatic embed.FS
+
+// staticprefix, url tempat aset dilayani
+const staticPrefix = "/static/"
+
+// immutablecache, cache header buat url yang ada hash-nya, isinya ga bakal pernah berubah
+const immutableCache = "public, max-age=31536000, immutable"
+
+// minprecompress, file lebih kecil dari ini ga usah dibikinin versi gzip/brotli
+const minPrecompress = 512
+
+// asset, satu file statis beserta varian yang udah dikompres
+type asset struct {
+	name        string // nama logis, misal css/base.css
+	url         string // /static/css/base.3f2a1b9c.css
+	etag        string
+	contentType string
+	raw         []byte
+	gzip        []byte // nil kalo ga ada untungnya
+	brotli      []byte // nil kalo ga ada untungnya
+}
+
+// assetset, semua aset, dicari lewat nama logis atau path fingerprint
+type assetSet struct {
+	byName map[string]*asset
+	byPath map[string]*asset // path setelah /static/, bisa fingerprint atau nama logis
+}
+
+// fingerprint, sisipin hash isi file sebelum ekstensi: css/base.css jadi css/base.3f2a1b9c.css
+// isi berubah = url berubah, jadi browser boleh nyimpen selamanya
+func fingerprint(name, hash string) string {
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// gzipbytes, kompres sekali pas start pake level paling tinggi
+// balikin nil kalo hasilnya ga lebih kecil
+func gzipBytes(b []byte) []byte {
+	if len(b) < minPrecompress {
+		return nil
+	}
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	zw.Write(b)
+	zw.Close()
+	if buf.Len() >= len(b) {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// brotlibytes, sama kayak gzipbytes tapi brotli, biasanya lebih kecil buat css/js
+// kompresnya lambat di level paling tinggi, tapi cuma sekali pas start
+func brotliBytes(b []byte) []byte {
+	if len(b) < minPrecompress {
+		return nil
+	}
+	var buf bytes.Buffer
+	bw := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	bw.Write(b)
+	bw.Close()
+	if buf.Len() >= len(b) {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// loadassets, baca semua file di fsys, hitung hash, sama siapin varian gzip sama brotli
+func loadAssets(fsys fs.FS) (assetSet, error) {
+	set := assetSet{byName: map[string]*asset{}, byPath: map[string]*asset{}}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		raw, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(raw)
+		hash := hex.EncodeToString(sum[:4])
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		a := &asset{
+			name:        name,
+			url:         staticPrefix + fingerprint(name, hash),
+			etag:        `"` + hash + `"`,
+			contentType: contentType,
+			raw:         raw,
+			gzip:        gzipBytes(raw),
+			brotli:      brotliBytes(raw),
+		}
+		set.byName[name] = a
+		set.byPath[name] = a
+		set.byPath[fingerprint(name, hash)] = a
+		return nil
+	})
+	return set, err
+}
+
+// url, nama logis ke url fingerprint, dipake template lewat {{asset "css/base.css"}}
+// nama yang salah jadi error biar ketauan pas render, bukan jadi link mati
+func (s assetSet) url(name string) (string, error) {
+	a, ok := s.byName[name]
+	if !ok {
+		return "", fmt.Errorf("aset %q ga ada", name)
+	}
+	return a.url, nil
+}
+
+// staticassets, aset bawaan, dibaca sekali pas program mulai
+var staticAssets = func() assetSet {
+	sub, _ := fs.Sub(embeddedStatic, "static")
+	set, err := loadAssets(sub)
+	if err != nil {
+		// isinya ikut ke-compile, jadi error di sini pasti salah build
+		panic(err)
+	}
+	return set
+}()
+
+// acceptsencoding, cek client nerima encoding tertentu (q=0 berarti nolak)
+func acceptsEncoding(r *http.Request, encoding string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(name, encoding) {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		return !ok || strings.Trim(q, "0.") != ""
+	}
+	return false
+}
+
+// newstatic, handler /static/
+// url fingerprint di-cache selamanya, nama logis (tanpa hash) tetep bisa diakses tapi harus revalidasi
+func newStatic(assets assetSet) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name := strings.TrimPrefix(r.URL.Path, staticPrefix)
+		a, ok := assets.byPath[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		// varian dipilih duluan, tiap encoding punya etag sendiri karena isinya beda
+		// brotli didahulukan karena hasilnya lebih kecil
+		body, encoding := a.raw, ""
+		switch {
+		case a.brotli != nil && acceptsEncoding(r, "br"):
+			body, encoding = a.brotli, "br"
+		case a.gzip != nil && acceptsEncoding(r, "gzip"):
+			body, encoding = a.gzip, "gzip"
+		}
+		etag := a.etag
+		if encoding != "" {
+			etag = strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+		}
+
+		h := w.Header()
+		h.Set("ETag", etag)
+		h.Set("Vary", "Accept-Encoding")
+		if a.url == staticPrefix+name {
+			h.Set("Cache-Control", immutableCache)
+		} else {
+			h.Set("Cache-Control", "no-cache")
+		}
+		if notModified(r, etag, time.Time{}) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		if encoding != "" {
+			h.Set("Content-Encoding", encoding)
+		}
+		h.Set("Content-Type", a.contentType)
+		h.Set("Content-Length", strconv.Itoa(len(body)))
+		h.Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodHead {
+			w.Write(body)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+(function () {
+    var form = document.querySelector('form[data-preview]');
+    var pane = document.getElementById('preview-pane');
+    if (!form || !pane || !window.fetch || !window.URLSearchParams) {
+        return;
+    }
+    var button = form.querySelector('[data-preview-toggle]');
+    var active = false;
+    var timer = null;
+    var latest = 0;
+
+    // refresh, kirim isi form ke /preview, respon lama yang telat dateng dibuang
+    function refresh() {
+        var id = ++latest;
+        fetch('/preview?fragment=1', {
+            method: 'POST',
+            body: new URLSearchParams(new FormData(form)),
+            credentials: 'same-origin'
+        }).then(function (res) {
+            return res.text().then(function (body) {
+                return { ok: res.ok, body: body };
+            });
+        }).then(function (res) {
+            if (id !== latest) {
+                return;
+            }
+            if (res.ok) {
+                pane.innerHTML = res.body;
+            } else {
+                pane.innerHTML = '';
+                var p = document.createElement('p');
+                p.className = 'preview-error';
+                p.textContent = res.body;
+                pane.appendChild(p);
+            }
+        });
+    }
+
+    button.addEventListener('click', function (e) {
+        e.preventDefault();
+        active = !active;
+        document.body.classList.toggle('previewing', active);
+        pane.hidden = !active;
+        button.textContent = active ? button.dataset.labelClose : button.dataset.labelOpen;
+        if (active) {
+            refresh();
+        }
+    });
+
+    form.addEventListener('input', function () {
+        if (!active) {
+            return;
+        }
+        clearTimeout(timer);
+        timer = setTimeout(refresh, 300);
+    });
+})();
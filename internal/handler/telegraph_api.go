@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+	"github.com/fhmptrdnd/private-blog/internal/service"
+	"github.com/fhmptrdnd/private-blog/internal/telegraph"
+)
+
+// batas-batas yang sama kayak api telegraph asli
+const (
+	maxAPIContent   = 64 << 10
+	maxAPITitle     = 256
+	maxAPIAuthor    = 128
+	maxAPIShortName = 32
+	maxAPIPageList  = 200
+)
+
+// apierror, kode error gaya telegraph ("PAGE_NOT_FOUND" dll), dikirim apa adanya ke client
+type apiError string
+
+func (e apiError) Error() string { return string(e) }
+
+// apiparams, ambil parameter request dari query, form, atau body json
+type apiParams func(key string) string
+
+// apimethod, satu method api telegraph
+type apiMethod func(p apiParams, r *http.Request) (any, error)
+
+// readapiparams, telegraph nerima parameter lewat query string, form, atau json
+// nilai json yang bukan string (misal content berupa array) disimpen mentah
+func readAPIParams(r *http.Request) (apiParams, error) {
+	values := r.URL.Query()
+	if r.Method == http.MethodPost {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			var body map[string]json.RawMessage
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				return nil, apiError("INVALID_JSON")
+			}
+			for k, raw := range body {
+				var s string
+				if json.Unmarshal(raw, &s) == nil {
+					values.Set(k, s)
+				} else {
+					values.Set(k, string(raw))
+				}
+			}
+		} else {
+			if err := r.ParseForm(); err != nil {
+				return nil, apiError("INVALID_FORM")
+			}
+			for k, v := range r.PostForm {
+				values[k] = v
+			}
+		}
+	}
+	return values.Get, nil
+}
+
+// apiresponse, envelope respon api telegraph
+type apiResponse struct {
+	OK     bool   `json:"ok"`
+	Result any    `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// writeapi, bungkus hasil pake envelope {ok, result} / {ok, error}
+// status http selalu 200 kayak api aslinya, error dibaca dari field ok
+func writeAPI(w http.ResponseWriter, result any, err error) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	resp := apiResponse{OK: err == nil, Result: result}
+	if err != nil {
+		var ae apiError
+		if !errors.As(err, &ae) {
+			// detail error internal cuma masuk log, client dapet kode umum
+			log.Printf("telegraph api: %v", err)
+			ae = apiError("INTERNAL_ERROR")
+		}
+		resp.Error = string(ae)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// newtelegraphapi, handler /api/{method} yang kompatibel sama api.telegra.ph
+// access token = owner id, jadi halaman dari api langsung muncul di /my-articles
+// (dan sebaliknya, isi cookie user_id bisa dipake jadi token)
+func NewTelegraphAPI(svc *service.ArticleService, prefix string, baseURL func(*http.Request) string) http.HandlerFunc {
+
+	// topage, ubah artikel jadi page, content cuma diisi kalo diminta
+	toPage := func(r *http.Request, a models.Article, withContent, canEdit bool) telegraph.Page {
+		text := strings.ReplaceAll(a.Content, "<br>", "\n")
+		p := telegraph.Page{
+			Path:        a.ID,
+			URL:         baseURL(r) + "/view/" + a.ID,
+			Title:       a.Title,
+			Description: a.Excerpt,
+			AuthorName:  a.Author,
+			Views:       a.Views,
+			CanEdit:     canEdit,
+		}
+		if withContent {
+			p.Content = telegraph.FromContent(text)
+		}
+		return p
+	}
+
+	// token, access token wajib buat method yang nulis atau baca punya sendiri
+	// cuma format bikinan createaccount yang diterima, token ngarang atau salah ketik ditolak
+	// (bukan diem-diem jadi akun baru yang kosong)
+	token := func(p apiParams) (string, error) {
+		t := strings.TrimSpace(p("access_token"))
+		if !validUserID(t) {
+			return "", apiError("ACCESS_TOKEN_INVALID")
+		}
+		return t, nil
+	}
+
+	// pagefields, validasi title, author, sama content buat createpage/editpage
+	pageFields := func(p apiParams) (title, author, content string, err error) {
+		title, author = strings.TrimSpace(p("title")), p("author_name")
+		switch {
+		case title == "":
+			return "", "", "", apiError("TITLE_REQUIRED")
+		case utf8.RuneCountInString(title) > maxAPITitle:
+			return "", "", "", apiError("TITLE_TOO_LONG")
+		case utf8.RuneCountInString(author) > maxAPIAuthor:
+			return "", "", "", apiError("AUTHOR_NAME_TOO_LONG")
+		case p("content") == "":
+			return "", "", "", apiError("CONTENT_REQUIRED")
+		case len(p("content")) > maxAPIContent:
+			return "", "", "", apiError("CONTENT_TOO_BIG")
+		}
+		var nodes []telegraph.Node
+		if err := json.Unmarshal([]byte(p("content")), &nodes); err != nil {
+			return "", "", "", apiError("CONTENT_FORMAT_INVALID")
+		}
+		content, _ = telegraph.ToContent(nodes)
+		if strings.TrimSpace(content) == "" {
+			return "", "", "", apiError("CONTENT_REQUIRED")
+		}
+		return title, author, content, nil
+	}
+
+	// pagepath, path bisa di url (/getPage/{path}) atau di parameter
+	pagePath := func(p apiParams, r *http.Request) string {
+		rest := strings.TrimPrefix(r.URL.Path, prefix)
+		if _, path, ok := strings.Cut(rest, "/"); ok && path != "" {
+			return path
+		}
+		return p("path")
+	}
+
+	returnContent := func(p apiParams) bool {
+		v, _ := strconv.ParseBool(p("return_content"))
+		return v
+	}
+
+	methods := map[string]apiMethod{
+		// createaccount, ga ada tabel akun: token baru = owner id baru
+		"createAccount": func(p apiParams, r *http.Request) (any, error) {
+			short := strings.TrimSpace(p("short_name"))
+			if short == "" {
+				return nil, apiError("SHORT_NAME_REQUIRED")
+			}
+			if utf8.RuneCountInString(short) > maxAPIShortName {
+				return nil, apiError("SHORT_NAME_TOO_LONG")
+			}
+			return telegraph.Account{
+				ShortName:   short,
+				AuthorName:  p("author_name"),
+				AuthorURL:   p("author_url"),
+				AccessToken: generateID(),
+			}, nil
+		},
+
+		"createPage": func(p apiParams, r *http.Request) (any, error) {
+			owner, err := token(p)
+			if err != nil {
+				return nil, err
+			}
+			title, author, content, err := pageFields(p)
+			if err != nil {
+				return nil, err
+			}
+			a, err := svc.Create(title, author, content, owner)
+			if err != nil {
+				return nil, err
+			}
+			return toPage(r, a, returnContent(p), true), nil
+		},
+
+		"editPage": func(p apiParams, r *http.Request) (any, error) {
+			owner, err := token(p)
+			if err != nil {
+				return nil, err
+			}
+			title, author, content, err := pageFields(p)
+			if err != nil {
+				return nil, err
+			}
+			a, err := svc.Update(pagePath(p, r), title, author, content, owner)
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, apiError("PAGE_NOT_FOUND")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return toPage(r, a, returnContent(p), true), nil
+		},
+
+		"getPage": func(p apiParams, r *http.Request) (any, error) {
+			a, err := svc.Get(pagePath(p, r))
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, apiError("PAGE_NOT_FOUND")
+			}
+			if err != nil {
+				return nil, err
+			}
+			return toPage(r, a, returnContent(p), false), nil
+		},
+
+		"getPageList": func(p apiParams, r *http.Request) (any, error) {
+			owner, err := token(p)
+			if err != nil {
+				return nil, err
+			}
+			offset, limit := 0, 50
+			if v := p("offset"); v != "" {
+				if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+					return nil, apiError("OFFSET_INVALID")
+				}
+			}
+			if v := p("limit"); v != "" {
+				if limit, err = strconv.Atoi(v); err != nil || limit < 0 || limit > maxAPIPageList {
+					return nil, apiError("LIMIT_INVALID")
+				}
+			}
+			articles, err := svc.ListMyArticles(owner)
+			if err != nil {
+				return nil, err
+			}
+			list := telegraph.PageList{TotalCount: len(articles), Pages: []telegraph.Page{}}
+			for i := offset; i < len(articles) && i < offset+limit; i++ {
+				list.Pages = append(list.Pages, toPage(r, articles[i], false, true))
+			}
+			return list, nil
+		},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		method, ok := methods[name]
+		if !ok {
+			writeAPI(w, nil, apiError("METHOD_NOT_FOUND"))
+			return
+		}
+		// content maksimal 64kb, sisanya buat parameter lain plus escaping json
+		r.Body = http.MaxBytesReader(w, r.Body, 2*maxAPIContent)
+		p, err := readAPIParams(r)
+		if err != nil {
+			writeAPI(w, nil, err)
+			return
+		}
+		result, err := method(p, r)
+		writeAPI(w, result, err)
+	}
+}
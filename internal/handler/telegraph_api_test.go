@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+	"github.com/fhmptrdnd/private-blog/internal/service"
+)
+
+// callapi, panggil satu method api telegraph, balikin envelope-nya
+func callAPI(t *testing.T, api http.HandlerFunc, method string, params url.Values) apiResponse {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	api(rec, httptest.NewRequest(http.MethodGet, "/api/"+method+"?"+params.Encode(), nil))
+	var resp apiResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	return resp
+}
+
+// token yang ga pernah dibikin createaccount harus ditolak, bukan jadi akun baru yang kosong
+func TestTelegraphAPIRejectsMadeUpTokens(t *testing.T) {
+	svc := service.NewArticleService(repository.NewMemoryRepo(), service.NewRealClock(), service.NewRealIDGen())
+	api := NewTelegraphAPI(svc, "/api/", func(*http.Request) string { return "http://example.com" })
+
+	acc := callAPI(t, api, "createAccount", url.Values{"short_name": {"Sandbox"}})
+	if !acc.OK {
+		t.Fatalf("createAccount: %+v", acc)
+	}
+	token := acc.Result.(map[string]any)["access_token"].(string)
+
+	page := url.Values{"title": {"Halo"}, "content": {`["Halo dunia"]`}}
+	page.Set("access_token", token)
+	if resp := callAPI(t, api, "createPage", page); !resp.OK {
+		t.Fatalf("createPage with issued token: %+v", resp)
+	}
+
+	for _, bad := range []string{"", "sandbox", token[:15], token + "0", "X" + token[1:], "zz" + token[2:]} {
+		page.Set("access_token", bad)
+		if resp := callAPI(t, api, "createPage", page); resp.OK || resp.Error != "ACCESS_TOKEN_INVALID" {
+			t.Errorf("createPage with token %q: %+v", bad, resp)
+		}
+		list := url.Values{"access_token": {bad}}
+		if resp := callAPI(t, api, "getPageList", list); resp.OK || resp.Error != "ACCESS_TOKEN_INVALID" {
+			t.Errorf("getPageList with token %q: %+v", bad, resp)
+		}
+	}
+}
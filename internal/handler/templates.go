@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/i18n"
+	"github.com/fhmptrdnd/private-blog/internal/service"
+)
+
+// embeddedtemplates, template bawaan yang ikut ke-compile ke binary
+//
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// sharedtemplates, layout sama partial yang dipake semua halaman
+var sharedTemplates = []string{
+	"layout.html",
+	"partials/header.html",
+	"partials/footer.html",
+	"partials/article_card.html",
+	"partials/article_body.html",
+	"partials/editor_preview.html",
+	"partials/views_chart.html",
+}
+
+// pagetemplates, nama halaman yang bisa di-render, filenya di pages/{nama}.html
+var pageTemplates = []string{"home", "view", "edit", "myarticles", "import", "preview", "stats"}
+
+// templateset, satu template per halaman, masing-masing udah gabung sama layout
+type templateSet map[string]*template.Template
+
+// localizedtemplates, templateset per bahasa
+// function t, plural, sama date udah nempel ke bahasanya pas parse,
+// soalnya html/template ga bisa di-clone lagi setelah dieksekusi
+type localizedTemplates map[i18n.Locale]templateSet
+
+// page, data yang dikirim ke layout. block halaman (title, head, style, content) cuma dapet data
+type page struct {
+	Theme       string // dipasang di <html data-theme>, kosong = ikut prefers-color-scheme
+	ThemeChoice string // pilihan pembaca sendiri, kosong = otomatis
+	Data        any
+}
+
+// localeoption, satu pilihan di pengganti bahasa
+type localeOption struct {
+	Code    string
+	Name    string
+	Current bool
+}
+
+// templatefuncs, function yang bisa dipanggil dari template, terikat ke satu bahasa
+func templateFuncs(l i18n.Locale) template.FuncMap {
+	return template.FuncMap{
+		"t":      func(key string, args ...any) string { return i18n.T(l, key, args...) },
+		"plural": func(key string, n int) string { return i18n.N(l, key, n) },
+		"date":   func(t time.Time, style string) string { return i18n.FormatDate(l, t, style) },
+		"lang":   func() string { return string(l) },
+		"themes": func() []string { return service.Themes },
+		"asset":  staticAssets.url,
+		"locales": func() []localeOption {
+			opts := make([]localeOption, 0, len(i18n.Supported))
+			for _, s := range i18n.Supported {
+				opts = append(opts, localeOption{Code: string(s), Name: i18n.T(s, "language.name"), Current: s == l})
+			}
+			return opts
+		},
+	}
+}
+
+// overlayfs, baca dari top dulu, kalo filenya ga ada baru dari base
+// jadi operator cukup naruh file yang mau diganti aja
+type overlayFS struct {
+	top, base fs.FS
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	f, err := o.top.Open(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return o.base.Open(name)
+	}
+	return f, err
+}
+
+// templatefs, template bawaan ditimpa sama isi dir (kalo diisi)
+func templateFS(dir string) fs.FS {
+	base, _ := fs.Sub(embeddedTemplates, "templates")
+	if dir == "" {
+		return base
+	}
+	return overlayFS{top: os.DirFS(dir), base: base}
+}
+
+// parsetemplates, parse layout sama partial sekali, terus tiap halaman dapet clone-nya sendiri
+// di-clone karena tiap halaman ngisi block yang sama (title, style, content) dengan isi beda
+func parseTemplates(fsys fs.FS, funcs template.FuncMap) (templateSet, error) {
+	parse := func(t *template.Template, name string) error {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		if _, err := t.New(name).Parse(string(b)); err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		return nil
+	}
+
+	shared := template.New("shared").Funcs(funcs)
+	for _, name := range sharedTemplates {
+		if err := parse(shared, name); err != nil {
+			return nil, err
+		}
+	}
+
+	set := templateSet{}
+	for _, page := range pageTemplates {
+		t, err := shared.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if err := parse(t, "pages/"+page+".html"); err != nil {
+			return nil, err
+		}
+		set[page] = t
+	}
+
+	// halaman error berdiri sendiri, ga ikut layout, biar tetep bisa tampil kalo layout-nya yang error
+	errPage := template.New("error").Funcs(funcs)
+	if err := parse(errPage, "error.html"); err != nil {
+		return nil, err
+	}
+	set["error"] = errPage
+	return set, nil
+}
+
+// parselocalized, parse semua template sekali buat tiap bahasa
+func parseLocalized(fsys fs.FS) (localizedTemplates, error) {
+	all := localizedTemplates{}
+	for _, l := range i18n.Supported {
+		set, err := parseTemplates(fsys, templateFuncs(l))
+		if err != nil {
+			return nil, err
+		}
+		all[l] = set
+	}
+	return all, nil
+}
+
+// newtemplateloader, function yang balikin template siap pakai
+// mode biasa: parse sekali di awal. mode dev: parse ulang tiap dipanggil,
+// jadi edit file di dir langsung keliatan tanpa restart
+func newTemplateLoader(dir string, dev bool) (func() (localizedTemplates, error), error) {
+	fsys := templateFS(dir)
+	if dev {
+		return func() (localizedTemplates, error) { return parseLocalized(fsys) }, nil
+	}
+	all, err := parseLocalized(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return func() (localizedTemplates, error) { return all, nil }, nil
+}
+
+// checktemplates, cek template (termasuk override dari dir) bisa di-parse
+// dipanggil pas start biar template yang rusak ketauan sebelum server jalan
+func CheckTemplates(dir string) error {
+	_, err := parseLocalized(templateFS(dir))
+	return err
+}
+
+// maxpooledbuffer, buffer yang lebih gede dari ini ga dibalikin ke pool
+// biar satu halaman raksasa ga bikin memori ketahan terus
+const maxPooledBuffer = 256 << 10
+
+// renderbuffers, buffer render dipake ulang antar request biar ga alokasi terus
+var renderBuffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// fallbackerrorpage, halaman error tanpa template, cuma dipake kalo template-nya sendiri gagal di-parse
+const fallbackErrorPage = `<!DOCTYPE html>
+<html lang="%s">
+<head><meta charset="UTF-8"><title>%s</title></head>
+<body style="font-family: Georgia, serif; text-align: center; padding: 60px 20px; color: #333;">
+    <h1>%s</h1>
+    <p>%s</p>
+    <p><a href="/">%s</a></p>
+</body>
+</html>
+`
+
+// errorpage, halaman error dalam bahasa pembaca, dari error.html di templateset bahasanya
+// all bisa nil kalo parse template gagal, jatuhnya ke fallbackerrorpage yang teksnya tetep diterjemahin
+func errorPage(all localizedTemplates, l i18n.Locale) []byte {
+	if t, ok := all[l]["error"]; ok {
+		var buf bytes.Buffer
+		if err := t.ExecuteTemplate(&buf, "error.html", nil); err == nil {
+			return buf.Bytes()
+		}
+	}
+	text := func(key string) string { return html.EscapeString(i18n.T(l, key)) }
+	return []byte(fmt.Sprintf(fallbackErrorPage, l, text("error.title"), text("error.title"),
+		text("error.render_failed"), text("error.back_home")))
+}
+
+// writeerrorpage, kirim halaman error 500 sama bahasanya
+func writeErrorPage(w http.ResponseWriter, all localizedTemplates, l i18n.Locale) {
+	w.Header().Set("Content-Language", string(l))
+	writeHTML(w, http.StatusInternalServerError, errorPage(all, l))
+}
+
+// writehtml, kirim html lengkap sama content-length
+func writeHTML(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// newrenderfunc, bikin function render yang nulis ke buffer dulu
+// response baru dikirim kalo template sukses, jadi ga ada halaman 200 yang kepotong setengah
+// bahasanya dipilih dari request lewat locale
+// tema juga diputusin di server biar halaman ga kedip pake tema yang salah pas dimuat
+// root itu template yang dijalanin, "layout" buat halaman penuh, "fragment" buat potongan html
+func newRenderFunc(templates func() (localizedTemplates, error), locale func(http.ResponseWriter, *http.Request) i18n.Locale,
+	theme func(http.ResponseWriter, *http.Request) string, root string) func(http.ResponseWriter, *http.Request, string, any) {
+	return func(w http.ResponseWriter, r *http.Request, name string, data any) {
+		l := locale(w, r)
+		all, err := templates()
+		if err != nil {
+			log.Printf("render %s: %v", name, err)
+			writeErrorPage(w, nil, l)
+			return
+		}
+		t, ok := all[l][name]
+		if !ok {
+			log.Printf("render %s: template ga ada", name)
+			writeErrorPage(w, all, l)
+			return
+		}
+
+		buf := renderBuffers.Get().(*bytes.Buffer)
+		buf.Reset()
+		defer func() {
+			if buf.Cap() <= maxPooledBuffer {
+				renderBuffers.Put(buf)
+			}
+		}()
+
+		choice := theme(w, r)
+		p := page{Theme: pageTheme(choice, data), ThemeChoice: choice, Data: data}
+		if err := t.ExecuteTemplate(buf, root, p); err != nil {
+			log.Printf("render %s: %v", name, err)
+			writeErrorPage(w, all, l)
+			return
+		}
+		w.Header().Set("Content-Language", string(l))
+		writeHTML(w, http.StatusOK, buf.Bytes())
+	}
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/fhmptrdnd/private-blog/internal/service"
+)
+
+// themecookie, nama cookie tema pilihan pembaca
+const themeCookie = "theme"
+
+// themeauto, nilai ?theme= buat balik ngikutin tema sistem
+const themeAuto = "auto"
+
+// themehinter, data halaman yang bawa saran tema dari penulis (halaman artikel)
+type themeHinter interface {
+	themeHint() string
+}
+
+// newthemefunc, bikin function yang balikin tema pilihan pembaca, kosong = ikut sistem
+// ?theme= di url disimpen ke cookie, ?theme=auto ngehapus pilihannya
+func newThemeFunc(c CookieOptions) func(http.ResponseWriter, *http.Request) string {
+	setCookie := func(w http.ResponseWriter, value string, maxAge int) {
+		http.SetCookie(w, &http.Cookie{
+			Name:     themeCookie,
+			Value:    value,
+			Path:     "/",
+			Domain:   c.Domain,
+			MaxAge:   maxAge,
+			Secure:   c.Secure,
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return func(w http.ResponseWriter, r *http.Request) string {
+		if q := r.URL.Query().Get("theme"); q == themeAuto {
+			setCookie(w, "", -1)
+		} else if theme := service.NormalizeTheme(q); theme != "" {
+			setCookie(w, theme, c.MaxAge)
+		}
+		return requestTheme(r)
+	}
+}
+
+// requesttheme, tema pilihan pembaca buat request ini, tanpa nyentuh response
+func requestTheme(r *http.Request) string {
+	if q := r.URL.Query().Get("theme"); q != "" {
+		if q == themeAuto {
+			return ""
+		}
+		if theme := service.NormalizeTheme(q); theme != "" {
+			return theme
+		}
+	}
+	if cookie, err := r.Cookie(themeCookie); err == nil {
+		return service.NormalizeTheme(cookie.Value)
+	}
+	return ""
+}
+
+// pagetheme, tema yang dipasang di <html data-theme>
+// pilihan pembaca menang, kalo ga ada pake saran penulis, kalo ga ada juga ikut prefers-color-scheme
+func pageTheme(choice string, data any) string {
+	if choice != "" {
+		return choice
+	}
+	if h, ok := data.(themeHinter); ok {
+		return h.themeHint()
+	}
+	return ""
+}
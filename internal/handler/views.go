@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// botmarkers, potongan user agent (huruf kecil) punya crawler, link unfurler, sama monitoring
+// mereka buka halaman tapi bukan pembaca
+var botMarkers = []string{
+	"bot", "crawl", "spider", "slurp", "scrapy", "archiver",
+	"facebookexternalhit", "facebookcatalog", "embedly", "whatsapp", "skypeuripreview", "vkshare",
+	"bitlybot", "iframely", "preview", "lighthouse", "pagespeed", "headlesschrome", "phantomjs",
+	"pingdom", "uptime", "monitor", "feedfetcher", "mediapartners",
+	"curl", "wget", "python-requests", "python-urllib", "go-http-client", "okhttp", "axios",
+	"java/", "libwww", "httpclient", "node-fetch",
+}
+
+// isbot, user agent kosong juga dianggap bot, browser beneran selalu ngirim
+func isBot(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	if strings.TrimSpace(ua) == "" {
+		return true
+	}
+	for _, m := range botMarkers {
+		if strings.Contains(ua, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isprefetch, request yang dikirim browser duluan sebelum pembaca beneran buka (prefetch/prerender)
+func isPrefetch(r *http.Request) bool {
+	for _, h := range []string{"Sec-Purpose", "Purpose", "X-Purpose", "X-Moz"} {
+		v := strings.ToLower(r.Header.Get(h))
+		if strings.Contains(v, "prefetch") || strings.Contains(v, "prerender") || strings.Contains(v, "preview") {
+			return true
+		}
+	}
+	return false
+}
+
+// countableview, request ini layak dihitung sebagai kunjungan atau ga
+// head, prefetch, sama bot ga dihitung. pemilik artikel dicek terpisah karena butuh artikelnya
+func countableView(r *http.Request) bool {
+	return r.Method == http.MethodGet && !isPrefetch(r) && !isBot(r.UserAgent())
+}
+
+// visitorkey, identitas pengunjung buat dedup views
+// pake cookie user kalo ada, kalo ga hash ip + user agent (ip mentah ga ikut disimpen)
+func visitorKey(r *http.Request, userID string) string {
+	if userID != "" {
+		return "u:" + userID
+	}
+	sum := sha256.Sum256([]byte(clientIP(r) + "\x00" + r.UserAgent()))
+	return "a:" + hex.EncodeToString(sum[:12])
+}
+
+// referrerdomain, domain asal pembaca dari header referer, cuma host-nya yang disimpen
+// path sama query bisa berisi data pribadi, jadi dibuang. kosong kalo langsung dibuka atau dari situs ini sendiri
+func referrerDomain(r *http.Request) string {
+	u, err := url.Parse(r.Referer())
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	self := r.Host
+	if h, _, err := net.SplitHostPort(self); err == nil {
+		self = h
+	}
+	if host == "" || host == strings.TrimPrefix(strings.ToLower(self), "www.") || !validHost(host) {
+		return ""
+	}
+	return host
+}
+
+// validhost, cuma huruf, angka, titik, strip, sama titik dua (ipv6) yang boleh masuk statistik
+// referer bisa diisi apa aja sama pengunjung, sisanya dianggap ga ada referrer
+func validHost(host string) bool {
+	if len(host) > 253 {
+		return false
+	}
+	for _, c := range host {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
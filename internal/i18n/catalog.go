@@ -0,0 +1,212 @@
+package i18n
+
+// languages, katalog semua bahasa
+// nambah teks baru: tambahin key yang sama di semua bahasa, kalo kelupaan jatuhnya ke bahasa default
+var languages = map[Locale]language{
+	ID: {
+		plural: noPlural,
+		months: [12]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+			"Juli", "Agustus", "September", "Oktober", "November", "Desember"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "Mei", "Jun",
+			"Jul", "Agu", "Sep", "Okt", "Nov", "Des"},
+		longDate:  dayMonthYear,
+		shortDate: dayMonthYear,
+		dayDate:   dayMonth,
+		messages: map[string]message{
+			"language.name": {other: "Bahasa Indonesia"},
+
+			"site.title":  {other: "Telegraph Clone"},
+			"site.footer": {other: "Telegraph Clone - Buat artikel dengan mudah"},
+
+			"home.title_placeholder":   {other: "Judul"},
+			"home.author_placeholder":  {other: "Nama Penulis"},
+			"home.content_placeholder": {other: "Ceritakan kisahmu..."},
+			"home.publish":             {other: "Publikasikan"},
+
+			"article.by":             {other: "Oleh"},
+			"article.reading_time":   {other: "%d menit baca"},
+			"article.words":          {other: "%d kata"},
+			"article.views":          {other: "%d tayangan"},
+			"article.edit":           {other: "Edit Artikel"},
+			"article.delete":         {other: "Hapus Artikel"},
+			"article.confirm_delete": {other: "Yakin ingin menghapus artikel ini?"},
+			"article.new":            {other: "Buat Artikel Baru"},
+
+			"edit.title":   {other: "Edit - %s"},
+			"edit.mode":    {other: "✏️ Mode Edit"},
+			"edit.confirm": {other: "Simpan perubahan artikel ini?"},
+			"edit.cancel":  {other: "Batal"},
+			"edit.save":    {other: "Simpan Perubahan"},
+
+			"my.title":       {other: "Artikel Saya"},
+			"my.count":       {other: "%d artikel"},
+			"my.empty":       {other: "Belum ada artikel."},
+			"my.empty_hint":  {other: "Mulai menulis artikel pertamamu!"},
+			"my.import":      {other: "Import Markdown"},
+			"my.download":    {other: "Download Semua (ZIP)"},
+			"my.total_views": {other: "Total tayangan"},
+			"my.recent":      {other: "Tayangan %d hari terakhir"},
+			"my.total_words": {other: "Total kata"},
+			"my.top":         {other: "Paling banyak dibaca"},
+			"my.stats":       {other: "Statistik"},
+
+			"stats.title":        {other: "Statistik - %s"},
+			"stats.heading":      {other: "Statistik Artikel"},
+			"stats.link":         {other: "Lihat Statistik"},
+			"stats.lifetime":     {other: "Total tayangan"},
+			"stats.recent":       {other: "%d hari terakhir"},
+			"stats.best_day":     {other: "Hari teramai"},
+			"stats.no_views":     {other: "Belum ada tayangan"},
+			"stats.chart_label":  {other: "Tayangan per hari, %d hari terakhir"},
+			"stats.referrers":    {other: "Sumber pembaca teratas"},
+			"stats.no_referrers": {other: "Belum ada pembaca dari situs lain."},
+			"stats.col_domain":   {other: "Situs"},
+			"stats.col_views":    {other: "Tayangan"},
+			"stats.other":        {other: "Situs lainnya"},
+			"stats.back":         {other: "Kembali ke artikel"},
+			"stats.privacy":      {other: "Hanya kamu yang bisa melihat halaman ini. Yang dicatat cuma jumlah per hari dan nama situs asal, tanpa IP pembaca."},
+
+			"import.title":            {other: "Import Artikel"},
+			"import.hint":             {other: "Upload file Markdown (.md) atau ZIP berisi file Markdown dengan YAML front matter (title, author, date/created, updated, id). Import ulang file yang sama ga bikin artikel dobel."},
+			"import.submit":           {other: "Import"},
+			"import.stopped":          {other: "Upload berhenti: %s"},
+			"import.summary":          {other: "%d berhasil, %d gagal"},
+			"import.col_file":         {other: "File"},
+			"import.col_status":       {other: "Status"},
+			"import.col_article":      {other: "Artikel"},
+			"import.status.created":   {other: "dibuat"},
+			"import.status.updated":   {other: "diperbarui"},
+			"import.status.unchanged": {other: "tidak berubah"},
+			"import.status.failed":    {other: "gagal"},
+
+			"theme.label":         {other: "Tema"},
+			"theme.auto":          {other: "Otomatis"},
+			"theme.light":         {other: "Terang"},
+			"theme.dark":          {other: "Gelap"},
+			"theme.sepia":         {other: "Sepia"},
+			"theme.article_label": {other: "Tema artikel"},
+			"theme.reader_choice": {other: "Ikut pilihan pembaca"},
+
+			"preview.button": {other: "Pratinjau"},
+			"preview.close":  {other: "Tutup Pratinjau"},
+			"preview.banner": {other: "Ini pratinjau, artikel belum disimpan."},
+			"preview.title":  {other: "Pratinjau - %s"},
+
+			"error.title":         {other: "Terjadi Kesalahan"},
+			"error.render_failed": {other: "Halaman ini gagal ditampilkan. Coba lagi sebentar lagi."},
+			"error.back_home":     {other: "Kembali ke beranda"},
+			"error.internal":      {other: "Terjadi kesalahan di server. Coba lagi sebentar lagi."},
+			"error.forbidden":     {other: "Artikel ini bukan milikmu."},
+			"error.too_large":     {other: "Artikel terlalu besar."},
+
+			"validation.title_required":    {other: "Judul wajib diisi."},
+			"validation.title_too_long":    {other: "Judul terlalu panjang (maksimal %d huruf)."},
+			"validation.author_too_long":   {other: "Nama penulis terlalu panjang (maksimal %d huruf)."},
+			"validation.content_required":  {other: "Isi artikel wajib diisi."},
+			"validation.content_too_large": {other: "Isi artikel terlalu besar (maksimal %d KB)."},
+		},
+	},
+	EN: {
+		plural: oneOther,
+		months: [12]string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun",
+			"Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		longDate:  monthDayYear,
+		shortDate: monthDayYear,
+		dayDate:   monthDay,
+		messages: map[string]message{
+			"language.name": {other: "English"},
+
+			"site.title":  {other: "Telegraph Clone"},
+			"site.footer": {other: "Telegraph Clone - Write articles with ease"},
+
+			"home.title_placeholder":   {other: "Title"},
+			"home.author_placeholder":  {other: "Your name"},
+			"home.content_placeholder": {other: "Tell your story..."},
+			"home.publish":             {other: "Publish"},
+
+			"article.by":             {other: "By"},
+			"article.reading_time":   {other: "%d min read"},
+			"article.words":          {one: "%d word", other: "%d words"},
+			"article.views":          {one: "%d view", other: "%d views"},
+			"article.edit":           {other: "Edit Article"},
+			"article.delete":         {other: "Delete Article"},
+			"article.confirm_delete": {other: "Are you sure you want to delete this article?"},
+			"article.new":            {other: "Write a New Article"},
+
+			"edit.title":   {other: "Edit - %s"},
+			"edit.mode":    {other: "✏️ Edit Mode"},
+			"edit.confirm": {other: "Save changes to this article?"},
+			"edit.cancel":  {other: "Cancel"},
+			"edit.save":    {other: "Save Changes"},
+
+			"my.title":       {other: "My Articles"},
+			"my.count":       {one: "%d article", other: "%d articles"},
+			"my.empty":       {other: "No articles yet."},
+			"my.empty_hint":  {other: "Start writing your first article!"},
+			"my.import":      {other: "Import Markdown"},
+			"my.download":    {other: "Download All (ZIP)"},
+			"my.total_views": {other: "Total views"},
+			"my.recent":      {other: "Views in the last %d days"},
+			"my.total_words": {other: "Total words"},
+			"my.top":         {other: "Most read"},
+			"my.stats":       {other: "Stats"},
+
+			"stats.title":        {other: "Stats - %s"},
+			"stats.heading":      {other: "Article Stats"},
+			"stats.link":         {other: "View Stats"},
+			"stats.lifetime":     {other: "Total views"},
+			"stats.recent":       {other: "Last %d days"},
+			"stats.best_day":     {other: "Busiest day"},
+			"stats.no_views":     {other: "No views yet"},
+			"stats.chart_label":  {other: "Views per day, last %d days"},
+			"stats.referrers":    {other: "Top referrers"},
+			"stats.no_referrers": {other: "No readers from other sites yet."},
+			"stats.col_domain":   {other: "Site"},
+			"stats.col_views":    {other: "Views"},
+			"stats.other":        {other: "Other sites"},
+			"stats.back":         {other: "Back to article"},
+			"stats.privacy":      {other: "Only you can see this page. Only daily counts and referring site names are recorded, never reader IPs."},
+
+			"import.title":            {other: "Import Articles"},
+			"import.hint":             {other: "Upload Markdown files (.md) or a ZIP of Markdown files with YAML front matter (title, author, date/created, updated, id). Importing the same file again does not create duplicates."},
+			"import.submit":           {other: "Import"},
+			"import.stopped":          {other: "Upload stopped: %s"},
+			"import.summary":          {other: "%d succeeded, %d failed"},
+			"import.col_file":         {other: "File"},
+			"import.col_status":       {other: "Status"},
+			"import.col_article":      {other: "Article"},
+			"import.status.created":   {other: "created"},
+			"import.status.updated":   {other: "updated"},
+			"import.status.unchanged": {other: "unchanged"},
+			"import.status.failed":    {other: "failed"},
+
+			"theme.label":         {other: "Theme"},
+			"theme.auto":          {other: "Auto"},
+			"theme.light":         {other: "Light"},
+			"theme.dark":          {other: "Dark"},
+			"theme.sepia":         {other: "Sepia"},
+			"theme.article_label": {other: "Article theme"},
+			"theme.reader_choice": {other: "Reader's choice"},
+
+			"preview.button": {other: "Preview"},
+			"preview.close":  {other: "Close Preview"},
+			"preview.banner": {other: "This is a preview, the article has not been saved."},
+			"preview.title":  {other: "Preview - %s"},
+
+			"error.title":         {other: "Something Went Wrong"},
+			"error.render_failed": {other: "This page could not be displayed. Please try again shortly."},
+			"error.back_home":     {other: "Back to home"},
+			"error.internal":      {other: "Something went wrong on the server. Please try again shortly."},
+			"error.forbidden":     {other: "This article is not yours."},
+			"error.too_large":     {other: "The article is too large."},
+
+			"validation.title_required":    {other: "A title is required."},
+			"validation.title_too_long":    {other: "The title is too long (at most %d characters)."},
+			"validation.author_too_long":   {other: "The author name is too long (at most %d characters)."},
+			"validation.content_required":  {other: "The article needs some content."},
+			"validation.content_too_large": {other: "The article content is too large (at most %d KB)."},
+		},
+	},
+}
@@ -0,0 +1,181 @@
+// package i18n, terjemahan teks tampilan (indonesia sama inggris),
+// format tanggal, bentuk jamak, sama milih bahasa dari accept-language
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// locale, kode bahasa yang didukung
+type Locale string
+
+const (
+	ID Locale = "id"
+	EN Locale = "en"
+)
+
+// default, bahasa kalo ga ada yang cocok
+const Default = ID
+
+// supported, semua bahasa yang ada katalognya, urutan ini dipake buat pilihan bahasa
+var Supported = []Locale{ID, EN}
+
+// parse, ubah string jadi locale, "en-US" dianggap "en"
+// ok false kalo bahasanya ga didukung
+func Parse(s string) (Locale, bool) {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "-")
+	base, _, _ = strings.Cut(base, "_")
+	for _, l := range Supported {
+		if string(l) == base {
+			return l, true
+		}
+	}
+	return "", false
+}
+
+// negotiate, pilih bahasa dari header accept-language sesuai bobot q
+// contoh "en-US,en;q=0.9,id;q=0.8" jadi en
+func Negotiate(header string) Locale {
+	type choice struct {
+		locale Locale
+		q      float64
+	}
+	var choices []choice
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if l, ok := Parse(tag); ok && q > 0 {
+			choices = append(choices, choice{l, q})
+		}
+	}
+	// stable biar yang nilainya sama tetep ikut urutan di header
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+	if len(choices) == 0 {
+		return Default
+	}
+	return choices[0].locale
+}
+
+// message, satu teks. other dipake kalo ga ada bentuk jamak yang lebih cocok
+type message struct {
+	one, other string
+}
+
+// pluralrule, pilih bentuk jamak dari angka
+type pluralRule func(n int) func(message) string
+
+var (
+	// bahasa indonesia ga punya bentuk jamak di angka ("1 artikel", "5 artikel")
+	noPlural pluralRule = func(int) func(message) string {
+		return func(m message) string { return m.other }
+	}
+	// bahasa inggris: 1 = one, selain itu other
+	oneOther pluralRule = func(n int) func(message) string {
+		return func(m message) string {
+			if n == 1 && m.one != "" {
+				return m.one
+			}
+			return m.other
+		}
+	}
+)
+
+// language, semua yang dibutuhin buat satu bahasa
+type language struct {
+	messages    map[string]message
+	plural      pluralRule
+	months      [12]string
+	shortMonths [12]string
+	longDate    func(t time.Time, months [12]string) string
+	shortDate   func(t time.Time, months [12]string) string
+	dayDate     func(t time.Time, months [12]string) string
+}
+
+func lookup(l Locale) language {
+	if lang, ok := languages[l]; ok {
+		return lang
+	}
+	return languages[Default]
+}
+
+// text, cari teks di bahasa l, kalo ga ada coba bahasa default, kalo tetep ga ada balikin key-nya
+// key yang kebalik ke layar gampang ketauan pas ngetes
+func text(l Locale, key string) (message, bool) {
+	if m, ok := lookup(l).messages[key]; ok {
+		return m, true
+	}
+	m, ok := languages[Default].messages[key]
+	return m, ok
+}
+
+// t, terjemahin key, args diisi pake fmt.sprintf kalo ada
+func T(l Locale, key string, args ...any) string {
+	m, ok := text(l, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return m.other
+	}
+	return fmt.Sprintf(m.other, args...)
+}
+
+// n, terjemahin key yang ada angkanya, bentuk jamak dipilih sesuai aturan bahasa
+// teksnya harus punya %d buat angkanya
+func N(l Locale, key string, n int) string {
+	m, ok := text(l, key)
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(lookup(l).plural(n)(m), n)
+}
+
+// dateformat, gaya tanggal yang bisa dipake di template
+const (
+	DateLong  = "long"  // 2 Januari 2006 / January 2, 2006
+	DateShort = "short" // 2 Jan 2006 / Jan 2, 2006
+	DateDay   = "day"   // 2 Jan / Jan 2, buat label grafik
+)
+
+// formatdate, tanggal pake nama bulan bahasa l
+func FormatDate(l Locale, t time.Time, style string) string {
+	lang := lookup(l)
+	switch style {
+	case DateShort:
+		return lang.shortDate(t, lang.shortMonths)
+	case DateDay:
+		return lang.dayDate(t, lang.shortMonths)
+	}
+	return lang.longDate(t, lang.months)
+}
+
+// dayMonthYear, urutan tanggal indonesia: 2 Januari 2006
+func dayMonthYear(t time.Time, months [12]string) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+}
+
+// dayMonth, tanggal tanpa tahun: 2 Jan
+func dayMonth(t time.Time, months [12]string) string {
+	return fmt.Sprintf("%d %s", t.Day(), months[t.Month()-1])
+}
+
+// monthDay, tanggal tanpa tahun versi inggris amerika: Jan 2
+func monthDay(t time.Time, months [12]string) string {
+	return fmt.Sprintf("%s %d", months[t.Month()-1], t.Day())
+}
+
+// monthDayYear, urutan tanggal inggris amerika: January 2, 2006
+func monthDayYear(t time.Time, months [12]string) string {
+	return fmt.Sprintf("%s %d, %d", months[t.Month()-1], t.Day(), t.Year())
+}
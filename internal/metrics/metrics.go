@@ -0,0 +1,253 @@
+// package metrics, kumpulin angka-angka runtime terus ditampilin
+// dalam format text exposition prometheus (tanpa library luar)
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultbuckets, batas histogram latency dalam detik
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// collector, apa aja yang bisa nulis dirinya sendiri ke output /metrics
+type collector interface {
+	write(w io.Writer)
+}
+
+// registry, tempat daftar semua metric
+// aman dipake dari banyak goroutine
+type Registry struct {
+	mu         sync.Mutex
+	names      []string
+	collectors map[string]collector
+}
+
+// newregistry, bikin registry kosong
+func NewRegistry() *Registry {
+	return &Registry{collectors: map[string]collector{}}
+}
+
+// register, daftarin collector, nama yang sama ga boleh dobel
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[name]; exists {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names = append(r.names, name)
+	r.collectors[name] = c
+}
+
+// counter, bikin counter vector dengan label tertentu
+func (r *Registry) Counter(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: map[string]*counterValue{}}
+	r.register(name, c)
+	return c
+}
+
+// histogram, bikin histogram vector dengan bucket dan label tertentu
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{name: name, help: help, labels: labels, buckets: buckets, values: map[string]*histogramValue{}}
+	r.register(name, h)
+	return h
+}
+
+// gaugefunc, gauge yang nilainya diambil dari function tiap kali di-scrape
+func (r *Registry) GaugeFunc(name, help string, fn func() float64) {
+	r.register(name, funcMetric{name: name, help: help, kind: "gauge", fn: fn})
+}
+
+// counterfunc, counter yang nilainya diambil dari function tiap kali di-scrape
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(name, funcMetric{name: name, help: help, kind: "counter", fn: fn})
+}
+
+// write, tulis semua metric dalam format prometheus, urut berdasarkan nama
+func (r *Registry) Write(w io.Writer) {
+	r.mu.Lock()
+	names := append([]string(nil), r.names...)
+	collectors := make([]collector, 0, len(names))
+	sort.Strings(names)
+	for _, n := range names {
+		collectors = append(collectors, r.collectors[n])
+	}
+	r.mu.Unlock()
+
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// handler, http handler buat endpoint /metrics
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.Write(w)
+	}
+}
+
+// countervec, counter yang dipisah per kombinasi label
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	keys   []string
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// inc, tambah counter 1
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// add, tambah counter sebanyak v
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cv, ok := c.values[key]
+	if !ok {
+		cv = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = cv
+		c.keys = append(c.keys, key)
+		sort.Strings(c.keys)
+	}
+	cv.value += v
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	writeHeader(w, c.name, c.help, "counter")
+	for _, k := range c.keys {
+		cv := c.values[k]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, cv.labelValues), formatFloat(cv.value))
+	}
+}
+
+// histogramvec, histogram yang dipisah per kombinasi label
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	keys   []string
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64 // jumlah observasi per bucket (belum kumulatif)
+	count       uint64
+	sum         float64
+}
+
+// observe, catet satu nilai ke histogram
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hv, ok := h.values[key]
+	if !ok {
+		hv = &histogramValue{
+			labelValues: append([]string(nil), labelValues...),
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = hv
+		h.keys = append(h.keys, key)
+		sort.Strings(h.keys)
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hv.counts[i]++
+			break
+		}
+	}
+	hv.count++
+	hv.sum += v
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	writeHeader(w, h.name, h.help, "histogram")
+	bucketLabels := append(append([]string(nil), h.labels...), "le")
+	for _, k := range h.keys {
+		hv := h.values[k]
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += hv.counts[i]
+			values := append(append([]string(nil), hv.labelValues...), formatFloat(upper))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, values), cumulative)
+		}
+		values := append(append([]string(nil), hv.labelValues...), "+Inf")
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, values), hv.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, hv.labelValues), formatFloat(hv.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, hv.labelValues), hv.count)
+	}
+}
+
+// funcmetric, metric tanpa label yang nilainya dari function
+type funcMetric struct {
+	name string
+	help string
+	kind string
+	fn   func() float64
+}
+
+func (f funcMetric) write(w io.Writer) {
+	writeHeader(w, f.name, f.help, f.kind)
+	fmt.Fprintf(w, "%s %s\n", f.name, formatFloat(f.fn()))
+}
+
+// writeheader, tulis baris # HELP sama # TYPE
+func writeHeader(w io.Writer, name, help, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// formatlabels, bikin {a="x",b="y"} dengan escaping sesuai spec
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	parts := make([]string, len(names))
+	for i, n := range names {
+		v := ""
+		if i < len(values) {
+			v = values[i]
+		}
+		parts[i] = n + `="` + escape.Replace(v) + `"`
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// formatfloat, format angka kayak yang diharapkan prometheus
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
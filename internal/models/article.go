@@ -13,4 +13,12 @@ type Article struct {
     Views     int       `json:"views"`
     OwnerID   string    `json:"owner_id"`
     DeletedAt *time.Time `json:"deleted_at,omitempty"` // nullable, buat soft delete
+
+    // metadata, dihitung service tiap create/update biar halaman list ga perlu content
+    WordCount      int    `json:"word_count"`
+    ReadingMinutes int    `json:"reading_minutes"`
+    Excerpt        string `json:"excerpt"`
+
+    // theme, saran tema baca dari penulis (light, dark, sepia), kosong = ikut pilihan pembaca
+    Theme string `json:"theme,omitempty"`
 }
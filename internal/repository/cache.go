@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// cacheoptions, setting buat withcache
+type CacheOptions struct {
+	Size          int           // maksimal artikel di cache (lru)
+	TTL           time.Duration // umur entry sebelum dianggap basi
+	FlushInterval time.Duration // seberapa sering views yang ketahan ditulis ke database
+}
+
+// defaultcacheoptions, setting cache bawaan
+func DefaultCacheOptions() CacheOptions {
+	return CacheOptions{Size: 1000, TTL: time.Minute, FlushInterval: 10 * time.Second}
+}
+
+// cachestats, angka-angka cache buat monitoring
+type CacheStats struct {
+	Hits         uint64
+	Misses       uint64
+	Entries      int
+	PendingViews int
+}
+
+// cachecontrol, function-function buat ngontrol cache dari luar
+type CacheControl struct {
+	Stats func() CacheStats
+	Flush func() error // tulis views yang ketahan sekarang juga
+	Stop  func() error // stop flush berkala terus flush terakhir kali
+}
+
+// statskey, satu baris statistik tayangan yang ketahan: artikel, hari (utc), sama domain referrer
+type statsKey struct {
+	id       string
+	day      time.Time
+	referrer string
+}
+
+// cacheentry, isi satu slot lru
+type cacheEntry struct {
+	id      string
+	article models.Article
+	expires time.Time
+}
+
+// withcache, bungkus repository dengan cache lru + ttl buat get
+// update/delete ngebuang entry-nya, incrementviews sama recordviewstats dikumpulin di memori terus di-flush berkala
+// semua state disimpan di closure dan dijaga satu mutex biar aman dipake banyak handler
+func WithCache(repo Repository, opts CacheOptions) (Repository, CacheControl) {
+	def := DefaultCacheOptions()
+	if opts.Size <= 0 {
+		opts.Size = def.Size
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = def.TTL
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = def.FlushInterval
+	}
+
+	var (
+		mu      sync.Mutex
+		lru     = list.New() // depan = paling baru dipake
+		entries = map[string]*list.Element{}
+		pending = map[string]int{}   // views yang belum ditulis ke database
+		writing = map[string]int{}   // views yang lagi ditulis flush, masih diitung sampe entry-nya dibuang
+		stats   = map[statsKey]int{} // statistik harian + referrer yang belum ditulis
+		version uint64               // naik tiap invalidasi, biar hasil get lama ga nimpa
+		hits    uint64
+		misses  uint64
+	)
+
+	// invalidate, buang entry dari cache (mu harus udah di-lock)
+	invalidate := func(id string) {
+		if el, ok := entries[id]; ok {
+			lru.Remove(el)
+			delete(entries, id)
+		}
+		version++
+	}
+
+	// withpending, tambahin views yang belum di-flush biar angka yang dibaca tetep akurat
+	withPending := func(a models.Article) models.Article {
+		a.Views += pending[a.ID] + writing[a.ID]
+		return a
+	}
+
+	get := func(id string) (models.Article, error) {
+		mu.Lock()
+		if el, ok := entries[id]; ok {
+			e := el.Value.(*cacheEntry)
+			if time.Now().Before(e.expires) {
+				lru.MoveToFront(el)
+				hits++
+				a := withPending(e.article)
+				mu.Unlock()
+				return a, nil
+			}
+			invalidate(id)
+		}
+		misses++
+		startVersion := version
+		mu.Unlock()
+
+		// query ke database di luar lock biar handler lain ga nunggu
+		a, err := repo.Get(id)
+		if err != nil {
+			return models.Article{}, err
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		// kalo ada update/delete selama query, hasilnya udah basi, jangan disimpen
+		if version == startVersion {
+			entries[id] = lru.PushFront(&cacheEntry{id: id, article: a, expires: time.Now().Add(opts.TTL)})
+			for lru.Len() > opts.Size {
+				oldest := lru.Back()
+				lru.Remove(oldest)
+				delete(entries, oldest.Value.(*cacheEntry).id)
+			}
+		}
+		return withPending(a), nil
+	}
+
+	// flush, tulis semua views yang ketahan, database ditulis tanpa megang mu
+	// selama ditulis angkanya pindah ke writing biar pembaca ga liat views-nya ilang,
+	// abis itu entry-nya dibuang soalnya angka di entry udah ketinggalan dari database
+	// statistik ikut ditulis bareng, jadi tiap kunjungan ga buka transaksi sendiri
+	flush := func() error {
+		mu.Lock()
+		batch := pending
+		pending = map[string]int{}
+		for id, n := range batch {
+			writing[id] += n
+		}
+		statsBatch := stats
+		stats = map[statsKey]int{}
+		mu.Unlock()
+
+		var errs []error
+		for id, n := range batch {
+			err := repo.IncrementViews(id, n)
+			mu.Lock()
+			if writing[id] -= n; writing[id] == 0 {
+				delete(writing, id)
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				pending[id] += n // balikin biar dicoba lagi nanti
+				errs = append(errs, err)
+			}
+			invalidate(id)
+			mu.Unlock()
+		}
+		for k, n := range statsBatch {
+			if err := repo.RecordViewStats(k.id, k.day, k.referrer, n); err != nil && !errors.Is(err, ErrNotFound) {
+				mu.Lock()
+				stats[k] += n
+				mu.Unlock()
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	// flush berkala di goroutine sendiri
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(opts.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				flush() // yang gagal udah dibalikin ke pending, dicoba lagi tick berikutnya
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	control := CacheControl{
+		Stats: func() CacheStats {
+			mu.Lock()
+			defer mu.Unlock()
+			total := 0
+			for _, n := range pending {
+				total += n
+			}
+			for _, n := range writing {
+				total += n
+			}
+			return CacheStats{Hits: hits, Misses: misses, Entries: lru.Len(), PendingViews: total}
+		},
+		Flush: flush,
+		Stop: func() error {
+			stopOnce.Do(func() {
+				close(stop)
+				<-done
+			})
+			return flush()
+		},
+	}
+
+	cached := Repository{
+		Create: func(a models.Article) error {
+			mu.Lock()
+			invalidate(a.ID)
+			mu.Unlock()
+			return repo.Create(a)
+		},
+		Get: get,
+		// ownerof jarang dipanggil (cuma import) dan harus liat baris yang udah dihapus, jadi ga di-cache
+		OwnerOf: repo.OwnerOf,
+		// update sama delete nulis ke database tanpa megang mu biar get lain ga ikut nunggu
+		// entry-nya dibuang setelah nulis: version naik, jadi get yang jalan barengan ga nyimpen data lama
+		// views ga ikut update (cuma lewat incrementviews), jadi pending aman dibiarin
+		Update: func(a models.Article) error {
+			err := repo.Update(a)
+			mu.Lock()
+			invalidate(a.ID)
+			mu.Unlock()
+			return err
+		},
+		Delete: func(id string) error {
+			err := repo.Delete(id)
+			mu.Lock()
+			invalidate(id)
+			if err == nil {
+				// artikel yang dihapus ga perlu views sama statistiknya lagi
+				delete(pending, id)
+				for k := range stats {
+					if k.id == id {
+						delete(stats, k)
+					}
+				}
+			}
+			mu.Unlock()
+			return err
+		},
+		ListByOwner: func(ownerID string) ([]models.Article, error) {
+			articles, err := repo.ListByOwner(ownerID)
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for i := range articles {
+				articles[i] = withPending(articles[i])
+			}
+			return articles, nil
+		},
+		EachByOwner: func(ownerID string, fn func(models.Article) error) error {
+			return repo.EachByOwner(ownerID, func(a models.Article) error {
+				mu.Lock()
+				a = withPending(a)
+				mu.Unlock()
+				return fn(a)
+			})
+		},
+		// sitemap ga butuh views, jadi langsung ke repository di bawahnya
+		EachPublic:  repo.EachPublic,
+		PublicStats: repo.PublicStats,
+		// incrementviews, cuma dicatet di memori, ditulis ke database pas flush
+		// tetep cek artikelnya ada (lewat cache) biar semantik errnotfound sama
+		IncrementViews: func(id string, n int) error {
+			if _, err := get(id); err != nil {
+				return err
+			}
+			mu.Lock()
+			pending[id] += n
+			mu.Unlock()
+			return nil
+		},
+		// recordviewstats, sama kayak incrementviews: dikumpulin per artikel, hari, sama referrer
+		// terus ditulis pas flush, jadi kunjungan ke artikel yang sama cuma jadi satu tulisan per flush
+		RecordViewStats: func(id string, at time.Time, referrer string, n int) error {
+			if _, err := get(id); err != nil {
+				return err
+			}
+			mu.Lock()
+			stats[statsKey{id: id, day: at.UTC().Truncate(24 * time.Hour), referrer: referrer}] += n
+			mu.Unlock()
+			return nil
+		},
+		// statistik yang dibaca bisa telat sampe satu flushinterval, cuma dipake halaman pemilik
+		DailyViews:   repo.DailyViews,
+		TopReferrers: repo.TopReferrers,
+		Ping:         repo.Ping,
+		Stats:        repo.Stats,
+		// close, stop flush berkala + flush terakhir dulu, baru tutup repository di dalemnya
+		Close: func() error {
+			return errors.Join(control.Stop(), repo.Close())
+		},
+	}
+	return cached, control
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+// update yang bawa views hasil get (udah termasuk pending) ga boleh bikin views ilang atau dobel pas flush
+func TestCacheUpdateKeepsPendingViews(t *testing.T) {
+	repo, control := WithCache(NewMemoryRepo(), DefaultCacheOptions())
+	defer control.Stop()
+	if err := repo.Create(fixture("a1", "owner", baseTime)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := repo.IncrementViews("a1", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, err := repo.Get("a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := control.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	a.Title = "Baru"
+	if err := repo.Update(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.IncrementViews("a1", 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := control.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.Get("a1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Views != 4 || got.Title != "Baru" {
+		t.Fatalf("got %q with %d views, want %q with 4", got.Title, got.Views, "Baru")
+	}
+}
+
+// statistik tayangan ditahan di cache dan ditulis sekali per flush, bukan tiap kunjungan
+func TestCacheBatchesViewStats(t *testing.T) {
+	inner := NewMemoryRepo()
+	writes := 0
+	counting := inner
+	counting.RecordViewStats = func(id string, at time.Time, referrer string, n int) error {
+		writes++
+		return inner.RecordViewStats(id, at, referrer, n)
+	}
+	repo, control := WithCache(counting, DefaultCacheOptions())
+	defer control.Stop()
+	if err := repo.Create(fixture("a1", "owner", baseTime)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := repo.RecordViewStats("a1", baseTime.Add(time.Duration(i)*time.Minute), "example.com", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if writes != 0 {
+		t.Fatalf("%d stats writes before flush, want 0", writes)
+	}
+	if err := expectNotFound(repo.RecordViewStats("missing", baseTime, "", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := control.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if writes != 1 {
+		t.Fatalf("%d stats writes after flush, want 1", writes)
+	}
+	refs, err := repo.TopReferrers("a1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 1 || refs[0] != (ReferrerViews{"example.com", 5}) {
+		t.Fatalf("got referrers %v, want example.com with 5", refs)
+	}
+}
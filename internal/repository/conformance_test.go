@@ -0,0 +1,474 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// conformancecase, satu skenario perilaku yang wajib sama di semua backend
+type conformanceCase struct {
+	name string
+	run  func(Repository) error
+}
+
+// testconformance, jalanin semua skenario ke newmemoryrepo sama newsqliterepo
+// tiap skenario dapet repository kosong sendiri, biar dua backend ini dijamin ga beda perilaku
+func TestConformance(t *testing.T) {
+	backends := []struct {
+		name    string
+		newRepo func(t *testing.T) Repository
+	}{
+		{"memory", func(*testing.T) Repository { return NewMemoryRepo() }},
+		{"sqlite", func(t *testing.T) Repository {
+			r, err := NewSQLiteRepo(filepath.Join(t.TempDir(), "conformance.db"))
+			if err != nil {
+				t.Fatalf("bikin repository: %v", err)
+			}
+			t.Cleanup(func() { r.Close() })
+			return r
+		}},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			for _, c := range conformanceCases {
+				t.Run(c.name, func(t *testing.T) {
+					if err := c.run(b.newRepo(t)); err != nil {
+						t.Fatal(err)
+					}
+				})
+			}
+		})
+	}
+}
+
+// fixture, artikel contoh buat skenario, waktunya dibuletin ke detik biar aman di semua backend
+func fixture(id, owner string, created time.Time) models.Article {
+	return models.Article{
+		ID:        id,
+		Title:     "Judul " + id,
+		Author:    "Penulis",
+		Content:   "isi<br>artikel",
+		CreatedAt: created,
+		UpdatedAt: created,
+		Views:     0,
+		OwnerID:   owner,
+
+		WordCount:      2,
+		ReadingMinutes: 1,
+		Excerpt:        "isi artikel",
+	}
+}
+
+var baseTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// sameArticle, bandingin dua artikel (waktu pake equal biar beda zona ga masalah)
+func sameArticle(got, want models.Article) error {
+	switch {
+	case got.ID != want.ID, got.Title != want.Title, got.Author != want.Author,
+		got.Content != want.Content, got.Views != want.Views, got.OwnerID != want.OwnerID,
+		got.WordCount != want.WordCount, got.ReadingMinutes != want.ReadingMinutes, got.Excerpt != want.Excerpt,
+		got.Theme != want.Theme:
+		return fmt.Errorf("got %+v, want %+v", got, want)
+	case !got.CreatedAt.Equal(want.CreatedAt), !got.UpdatedAt.Equal(want.UpdatedAt):
+		return fmt.Errorf("timestamps got %v/%v, want %v/%v", got.CreatedAt, got.UpdatedAt, want.CreatedAt, want.UpdatedAt)
+	case got.DeletedAt != nil:
+		return fmt.Errorf("deleted_at should be nil, got %v", *got.DeletedAt)
+	}
+	return nil
+}
+
+// expectNotFound, pastiin error-nya errnotfound
+func expectNotFound(err error) error {
+	if !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("expected ErrNotFound, got %v", err)
+	}
+	return nil
+}
+
+var conformanceCases = []conformanceCase{
+	{"create then get returns same article", func(r Repository) error {
+		a := fixture("a1", "owner", baseTime)
+		a.Views = 3
+		a.Theme = "sepia"
+		if err := r.Create(a); err != nil {
+			return err
+		}
+		got, err := r.Get("a1")
+		if err != nil {
+			return err
+		}
+		return sameArticle(got, a)
+	}},
+
+	{"get missing returns ErrNotFound", func(r Repository) error {
+		_, err := r.Get("missing")
+		return expectNotFound(err)
+	}},
+
+	{"duplicate id is rejected", func(r Repository) error {
+		a := fixture("a1", "owner", baseTime)
+		if err := r.Create(a); err != nil {
+			return err
+		}
+		if err := r.Create(a); err == nil {
+			return errors.New("second create should fail")
+		}
+		return nil
+	}},
+
+	{"update changes editable fields only", func(r Repository) error {
+		a := fixture("a1", "owner", baseTime)
+		if err := r.Create(a); err != nil {
+			return err
+		}
+		changed := a
+		changed.Title = "Baru"
+		changed.Author = "Orang Lain"
+		changed.Content = "isi baru"
+		changed.WordCount, changed.ReadingMinutes, changed.Excerpt = 5, 2, "cuplikan baru"
+		changed.Theme = "dark"
+		changed.UpdatedAt = baseTime.Add(time.Hour)
+		changed.Views = 7                                // ga boleh ikut berubah, views cuma lewat incrementviews
+		changed.CreatedAt = baseTime.Add(48 * time.Hour) // ga boleh ikut berubah
+		if err := r.Update(changed); err != nil {
+			return err
+		}
+		got, err := r.Get("a1")
+		if err != nil {
+			return err
+		}
+		want := changed
+		want.CreatedAt = a.CreatedAt
+		want.Views = a.Views
+		return sameArticle(got, want)
+	}},
+
+	{"update with wrong owner returns ErrNotFound", func(r Repository) error {
+		a := fixture("a1", "owner", baseTime)
+		if err := r.Create(a); err != nil {
+			return err
+		}
+		stolen := a
+		stolen.OwnerID = "intruder"
+		stolen.Title = "Dibajak"
+		if err := expectNotFound(r.Update(stolen)); err != nil {
+			return err
+		}
+		got, err := r.Get("a1")
+		if err != nil {
+			return err
+		}
+		return sameArticle(got, a)
+	}},
+
+	{"update missing returns ErrNotFound", func(r Repository) error {
+		return expectNotFound(r.Update(fixture("missing", "owner", baseTime)))
+	}},
+
+	{"delete hides article and is not repeatable", func(r Repository) error {
+		if err := r.Create(fixture("a1", "owner", baseTime)); err != nil {
+			return err
+		}
+		if err := r.Delete("a1"); err != nil {
+			return err
+		}
+		if _, err := r.Get("a1"); expectNotFound(err) != nil {
+			return fmt.Errorf("get after delete: %w", expectNotFound(err))
+		}
+		if err := expectNotFound(r.Delete("a1")); err != nil {
+			return fmt.Errorf("second delete: %w", err)
+		}
+		return expectNotFound(r.Delete("missing"))
+	}},
+
+	{"owner of sees deleted articles", func(r Repository) error {
+		if err := r.Create(fixture("a1", "owner", baseTime)); err != nil {
+			return err
+		}
+		if owner, deleted, err := r.OwnerOf("a1"); err != nil || owner != "owner" || deleted {
+			return fmt.Errorf("active: got %q %v %v", owner, deleted, err)
+		}
+		if err := r.Delete("a1"); err != nil {
+			return err
+		}
+		if owner, deleted, err := r.OwnerOf("a1"); err != nil || owner != "owner" || !deleted {
+			return fmt.Errorf("deleted: got %q %v %v", owner, deleted, err)
+		}
+		_, _, err := r.OwnerOf("missing")
+		return expectNotFound(err)
+	}},
+
+	{"list by owner filters, hides deleted and orders newest first", func(r Repository) error {
+		fixtures := []models.Article{
+			fixture("old", "owner", baseTime),
+			fixture("new", "owner", baseTime.Add(2*time.Hour)),
+			fixture("mid", "owner", baseTime.Add(time.Hour)),
+			fixture("other", "someone-else", baseTime.Add(3*time.Hour)),
+			fixture("gone", "owner", baseTime.Add(4*time.Hour)),
+		}
+		for _, a := range fixtures {
+			if err := r.Create(a); err != nil {
+				return err
+			}
+		}
+		if err := r.Delete("gone"); err != nil {
+			return err
+		}
+		list, err := r.ListByOwner("owner")
+		if err != nil {
+			return err
+		}
+		want := []string{"new", "mid", "old"}
+		if len(list) != len(want) {
+			return fmt.Errorf("got %d articles, want %d", len(list), len(want))
+		}
+		for i, id := range want {
+			if list[i].ID != id {
+				return fmt.Errorf("position %d: got %s, want %s", i, list[i].ID, id)
+			}
+			// daftar ga bawa content, tapi metadata tetep ada
+			if list[i].Content != "" || list[i].Excerpt != "isi artikel" || list[i].WordCount != 2 {
+				return fmt.Errorf("position %d: want metadata without content, got %+v", i, list[i])
+			}
+		}
+		return nil
+	}},
+
+	{"each public pages through active articles of every owner", func(r Repository) error {
+		st, err := r.PublicStats()
+		if err != nil {
+			return err
+		}
+		if st.Count != 0 || !st.LastUpdated.IsZero() {
+			return fmt.Errorf("empty repository stats: got %+v", st)
+		}
+		for i, id := range []string{"p2", "p1", "p3", "gone"} {
+			a := fixture(id, fmt.Sprint("owner", i), baseTime.Add(time.Duration(i)*time.Hour))
+			a.UpdatedAt = baseTime.Add(time.Duration(10-i) * time.Hour)
+			if err := r.Create(a); err != nil {
+				return err
+			}
+		}
+		// p0 dibikin barengan p2 biar urutan cadangan by id kepake
+		same := fixture("p0", "owner9", baseTime)
+		if err := r.Create(same); err != nil {
+			return err
+		}
+		if err := r.Delete("gone"); err != nil {
+			return err
+		}
+
+		var pages []string
+		for offset := 0; offset < 6; offset += 2 {
+			var page []string
+			err := r.EachPublic(offset, 2, func(a models.Article) error {
+				if a.Content != "" || a.Title != "" {
+					return fmt.Errorf("each public should only fill id and dates, got %+v", a)
+				}
+				page = append(page, a.ID)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			pages = append(pages, fmt.Sprint(page))
+		}
+		if got := fmt.Sprint(pages); got != "[[p0 p2] [p1 p3] []]" {
+			return fmt.Errorf("pages %s, want [[p0 p2] [p1 p3] []]", got)
+		}
+
+		st, err = r.PublicStats()
+		if err != nil {
+			return err
+		}
+		if st.Count != 4 || !st.LastUpdated.Equal(baseTime.Add(10*time.Hour)) {
+			return fmt.Errorf("stats got %+v, want 4 articles updated at %v", st, baseTime.Add(10*time.Hour))
+		}
+		return nil
+	}},
+
+	{"each by owner visits the same articles as list and stops on error", func(r Repository) error {
+		for i, id := range []string{"a", "b", "c"} {
+			if err := r.Create(fixture(id, "owner", baseTime.Add(time.Duration(i)*time.Hour))); err != nil {
+				return err
+			}
+		}
+		if err := r.Create(fixture("x", "other", baseTime)); err != nil {
+			return err
+		}
+		var seen []string
+		err := r.EachByOwner("owner", func(a models.Article) error {
+			seen = append(seen, a.ID)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if fmt.Sprint(seen) != "[c b a]" {
+			return fmt.Errorf("visited %v, want [c b a]", seen)
+		}
+
+		stop := errors.New("stop")
+		count := 0
+		err = r.EachByOwner("owner", func(models.Article) error {
+			count++
+			return stop
+		})
+		if !errors.Is(err, stop) || count != 1 {
+			return fmt.Errorf("expected to stop after first article with fn error, got %v after %d", err, count)
+		}
+		return nil
+	}},
+
+	{"list by unknown owner is empty", func(r Repository) error {
+		list, err := r.ListByOwner("nobody")
+		if err != nil {
+			return err
+		}
+		if len(list) != 0 {
+			return fmt.Errorf("got %d articles, want 0", len(list))
+		}
+		return nil
+	}},
+
+	{"increment views adds to active articles only", func(r Repository) error {
+		if err := r.Create(fixture("a1", "owner", baseTime)); err != nil {
+			return err
+		}
+		if err := r.IncrementViews("a1", 1); err != nil {
+			return err
+		}
+		if err := r.IncrementViews("a1", 4); err != nil {
+			return err
+		}
+		got, err := r.Get("a1")
+		if err != nil {
+			return err
+		}
+		if got.Views != 5 {
+			return fmt.Errorf("got %d views, want 5", got.Views)
+		}
+		if err := expectNotFound(r.IncrementViews("missing", 1)); err != nil {
+			return err
+		}
+		if err := r.Delete("a1"); err != nil {
+			return err
+		}
+		return expectNotFound(r.IncrementViews("a1", 1))
+	}},
+
+	{"view stats aggregate per day and referrer", func(r Repository) error {
+		for _, a := range []models.Article{fixture("a1", "owner", baseTime), fixture("a2", "owner", baseTime), fixture("b1", "other", baseTime)} {
+			if err := r.Create(a); err != nil {
+				return err
+			}
+		}
+		day1 := baseTime.Add(2 * time.Hour)
+		day2 := day1.Add(24 * time.Hour)
+		hits := []struct {
+			id       string
+			at       time.Time
+			referrer string
+			n        int
+		}{
+			{"a1", day1, "example.com", 1},
+			{"a1", day1.Add(time.Hour), "example.com", 2},
+			{"a1", day2, "", 1},
+			{"a1", day2, "news.ycombinator.com", 3},
+			{"a2", day1, "z.example", 3},
+			{"b1", day1, "example.com", 1},
+		}
+		for _, h := range hits {
+			if err := r.RecordViewStats(h.id, h.at, h.referrer, h.n); err != nil {
+				return err
+			}
+		}
+		if err := expectNotFound(r.RecordViewStats("missing", day1, "", 1)); err != nil {
+			return err
+		}
+
+		days, err := r.DailyViews("owner", "", baseTime)
+		if err != nil {
+			return err
+		}
+		want := []DailyViews{
+			{ArticleID: "a1", Day: day1.Truncate(24 * time.Hour), Views: 3},
+			{ArticleID: "a2", Day: day1.Truncate(24 * time.Hour), Views: 3},
+			{ArticleID: "a1", Day: day2.Truncate(24 * time.Hour), Views: 4},
+		}
+		if len(days) != len(want) {
+			return fmt.Errorf("got %d daily rows, want %d: %v", len(days), len(want), days)
+		}
+		for i := range want {
+			if days[i].ArticleID != want[i].ArticleID || !days[i].Day.Equal(want[i].Day) || days[i].Views != want[i].Views {
+				return fmt.Errorf("daily row %d = %+v, want %+v", i, days[i], want[i])
+			}
+		}
+
+		days, err = r.DailyViews("owner", "a1", day2)
+		if err != nil {
+			return err
+		}
+		if len(days) != 1 || days[0].Views != 4 {
+			return fmt.Errorf("filtered by article and since got %+v, want one row with 4 views", days)
+		}
+
+		refs, err := r.TopReferrers("a1", 10)
+		if err != nil {
+			return err
+		}
+		if len(refs) != 2 || refs[0] != (ReferrerViews{"example.com", 3}) || refs[1] != (ReferrerViews{"news.ycombinator.com", 3}) {
+			return fmt.Errorf("got referrers %v, want example.com and news.ycombinator.com with 3 each", refs)
+		}
+		if refs, err = r.TopReferrers("a1", 1); err != nil || len(refs) != 1 {
+			return fmt.Errorf("limit 1 got %v (err %v)", refs, err)
+		}
+
+		// artikel yang dihapus statistiknya ikut ilang
+		if err := r.Delete("a2"); err != nil {
+			return err
+		}
+		if days, err = r.DailyViews("owner", "a2", baseTime); err != nil || len(days) != 0 {
+			return fmt.Errorf("deleted article still has daily rows %v (err %v)", days, err)
+		}
+		if refs, err = r.TopReferrers("a2", 10); err != nil || len(refs) != 0 {
+			return fmt.Errorf("deleted article still has referrers %v (err %v)", refs, err)
+		}
+		return expectNotFound(r.RecordViewStats("a2", day1, "", 1))
+	}},
+
+	{"referrers beyond the cap are bucketed into other", func(r Repository) error {
+		if err := r.Create(fixture("a1", "owner", baseTime)); err != nil {
+			return err
+		}
+		for i := 0; i < MaxReferrers+5; i++ {
+			if err := r.RecordViewStats("a1", baseTime, fmt.Sprintf("site%02d.example", i), 1); err != nil {
+				return err
+			}
+		}
+		// domain yang udah kecatet tetep nambah sendiri walau jatahnya udah penuh
+		if err := r.RecordViewStats("a1", baseTime, "site00.example", 2); err != nil {
+			return err
+		}
+		refs, err := r.TopReferrers("a1", 2*MaxReferrers)
+		if err != nil {
+			return err
+		}
+		if len(refs) != MaxReferrers+1 {
+			return fmt.Errorf("got %d referrer rows, want %d", len(refs), MaxReferrers+1)
+		}
+		if refs[0] != (ReferrerViews{ReferrerOther, 5}) || refs[1] != (ReferrerViews{"site00.example", 3}) {
+			return fmt.Errorf("got top referrers %v, want other with 5 then site00.example with 3", refs[:2])
+		}
+		return nil
+	}},
+
+	{"ping succeeds on fresh repository", func(r Repository) error {
+		return r.Ping()
+	}},
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// errcircuitopen, dibalikin pas circuit breaker lagi kebuka (database dianggap lagi bermasalah)
+var ErrCircuitOpen = errors.New("repository circuit open")
+
+// decorator, function yang nerima repository dan return repository baru
+// sama kayak handler.middleware tapi buat layer data
+type Decorator func(Repository) Repository
+
+// decorate, pasang beberapa decorator ke repository
+// decorator pertama jadi yang paling luar, sama kayak urutan di handler.chain
+// implementasi rekursif: base case + recursive case
+func Decorate(repo Repository, decorators ...Decorator) Repository {
+	// base case: kalo ga ada decorator lagi, return repository
+	if len(decorators) == 0 {
+		return repo
+	}
+	// recursive case: apply decorator terakhir, lalu rekursi ke sisanya
+	last := decorators[len(decorators)-1]
+	rest := decorators[:len(decorators)-1]
+	return Decorate(last(repo), rest...)
+}
+
+// withtiming, laporin durasi tiap operasi ke observe (buat metrics)
+func WithTiming(observe ObserveFunc) Decorator {
+	return func(repo Repository) Repository {
+		return Instrument(repo, observe)
+	}
+}
+
+// withslowquerylog, catet operasi yang lebih lama dari threshold
+// logf biasanya fmt.Printf, dijadiin parameter biar bisa diganti
+func WithSlowQueryLog(threshold time.Duration, logf func(format string, args ...any)) Decorator {
+	return func(repo Repository) Repository {
+		return Instrument(repo, func(op string, d time.Duration, err error) {
+			if d >= threshold {
+				logf("[SLOW] repository %s took %s (err: %v)\n", op, d, err)
+			}
+		})
+	}
+}
+
+// isbusy, cek error dari sqlite karena database lagi dikunci koneksi lain
+func IsBusy(err error) bool {
+	var se *sqlite.Error
+	if !errors.As(err, &se) {
+		return false
+	}
+	// code bisa extended code, 8 bit bawah itu primary code-nya
+	code := se.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
+
+// withretry, ulangin operasi yang gagal karena sqlite_busy
+// operasi each* cuma diulang kalo fn belum sempet dipanggil
+// jeda antar percobaan dobel tiap kali (exponential backoff)
+func WithRetry(attempts int, backoff time.Duration) Decorator {
+	if attempts < 1 {
+		attempts = 1
+	}
+	return func(repo Repository) Repository {
+		return wrapAll(repo, func(op string, call func() error) error {
+			wait := backoff
+			var err error
+			for i := 0; i < attempts; i++ {
+				// error dari fn, atau database gagal setelah fn sempet jalan: ngulang bakal manggil fn dobel
+				var ie *iterationError
+				if err = call(); !IsBusy(err) || errors.As(err, &ie) {
+					return err
+				}
+				if i < attempts-1 {
+					time.Sleep(wait)
+					wait *= 2
+				}
+			}
+			return fmt.Errorf("%s: gave up after %d attempts: %w", op, attempts, err)
+		})
+	}
+}
+
+// withcircuitbreaker, stop manggil database sementara kalo gagal terus-terusan
+// setelah threshold kegagalan berturut-turut, semua operasi langsung dapet errcircuitopen
+// sampe cooldown lewat, terus satu operasi dicoba lagi (half-open) buat ngetes
+// errnotfound ga dihitung gagal karena itu jawaban normal, bukan database rusak
+// error dari fn operasi each* (misal client download putus) juga bukan salah database
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Decorator {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return func(repo Repository) Repository {
+		var (
+			mu        sync.Mutex
+			failures  int
+			openUntil time.Time
+			probing   bool // lagi ada satu operasi percobaan pas half-open
+		)
+
+		// allow, boleh lanjut manggil database atau ngga
+		allow := func(now time.Time) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			if failures < threshold {
+				return true
+			}
+			if now.Before(openUntil) || probing {
+				return false
+			}
+			probing = true
+			return true
+		}
+
+		// record, update state breaker dari hasil operasi
+		record := func(err error, now time.Time) {
+			mu.Lock()
+			defer mu.Unlock()
+			probing = false
+			if err == nil || errors.Is(err, ErrNotFound) || isCallbackError(err) {
+				failures = 0
+				return
+			}
+			failures++
+			if failures >= threshold {
+				openUntil = now.Add(cooldown)
+			}
+		}
+
+		return wrapAll(repo, func(op string, call func() error) error {
+			if !allow(time.Now()) {
+				return fmt.Errorf("%s: %w", op, ErrCircuitOpen)
+			}
+			err := call()
+			record(err, time.Now())
+			return err
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// error dari fn each* (misal client download putus) ga boleh bikin breaker kebuka
+func TestCircuitBreakerIgnoresCallbackErrors(t *testing.T) {
+	inner := NewMemoryRepo()
+	if err := inner.Create(fixture("a1", "owner", baseTime)); err != nil {
+		t.Fatal(err)
+	}
+	var observed []error
+	repo := Decorate(inner,
+		WithTiming(func(_ string, _ time.Duration, err error) { observed = append(observed, err) }),
+		WithCircuitBreaker(1, time.Hour),
+		WithRetry(3, time.Millisecond),
+	)
+
+	broken := errors.New("broken pipe")
+	for i := 0; i < 3; i++ {
+		calls := 0
+		err := repo.EachByOwner("owner", func(models.Article) error {
+			calls++
+			return broken
+		})
+		if err != broken {
+			t.Fatalf("got %v, want the callback error unchanged", err)
+		}
+		if calls != 1 {
+			t.Fatalf("fn called %d times, want 1", calls)
+		}
+	}
+	if _, err := repo.Get("a1"); err != nil {
+		t.Fatalf("get after callback errors: %v", err)
+	}
+	for _, err := range observed {
+		if err != nil {
+			t.Fatalf("timing observed %v, callback errors are not database errors", err)
+		}
+	}
+}
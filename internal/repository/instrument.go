@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// observefunc, dipanggil tiap selesai operasi repository
+// op itu nama operasi ("create", "get", ...), d durasinya
+type ObserveFunc func(op string, d time.Duration, err error)
+
+// aroundfunc, bungkus satu pemanggilan operasi
+// call itu operasi aslinya, hasilnya (kalo ada) udah ditangkep di closure pemanggil
+type aroundFunc func(op string, call func() error) error
+
+// wrapall, bungkus semua function di repository pake around yang sama
+// ini inti dari semua dekorator: satu tempat yang tau daftar field repository
+func wrapAll(repo Repository, around aroundFunc) Repository {
+	return Repository{
+		Create: func(a models.Article) error {
+			return around("create", func() error { return repo.Create(a) })
+		},
+		Get: func(id string) (models.Article, error) {
+			var a models.Article
+			err := around("get", func() error {
+				var err error
+				a, err = repo.Get(id)
+				return err
+			})
+			return a, err
+		},
+		OwnerOf: func(id string) (string, bool, error) {
+			var owner string
+			var deleted bool
+			err := around("owner_of", func() error {
+				var err error
+				owner, deleted, err = repo.OwnerOf(id)
+				return err
+			})
+			return owner, deleted, err
+		},
+		Update: func(a models.Article) error {
+			return around("update", func() error { return repo.Update(a) })
+		},
+		Delete: func(id string) error {
+			return around("delete", func() error { return repo.Delete(id) })
+		},
+		ListByOwner: func(ownerID string) ([]models.Article, error) {
+			var articles []models.Article
+			err := around("list_by_owner", func() error {
+				var err error
+				articles, err = repo.ListByOwner(ownerID)
+				return err
+			})
+			return articles, err
+		},
+		EachByOwner: func(ownerID string, fn func(models.Article) error) error {
+			return aroundEach(around, "each_by_owner", fn, func(fn func(models.Article) error) error {
+				return repo.EachByOwner(ownerID, fn)
+			})
+		},
+		EachPublic: func(offset, limit int, fn func(models.Article) error) error {
+			return aroundEach(around, "each_public", fn, func(fn func(models.Article) error) error {
+				return repo.EachPublic(offset, limit, fn)
+			})
+		},
+		PublicStats: func() (PublicStats, error) {
+			var st PublicStats
+			err := around("public_stats", func() error {
+				var err error
+				st, err = repo.PublicStats()
+				return err
+			})
+			return st, err
+		},
+		IncrementViews: func(id string, n int) error {
+			return around("increment_views", func() error { return repo.IncrementViews(id, n) })
+		},
+		RecordViewStats: func(id string, at time.Time, referrer string, n int) error {
+			return around("record_view_stats", func() error { return repo.RecordViewStats(id, at, referrer, n) })
+		},
+		DailyViews: func(ownerID, articleID string, since time.Time) ([]DailyViews, error) {
+			var days []DailyViews
+			err := around("daily_views", func() error {
+				var err error
+				days, err = repo.DailyViews(ownerID, articleID, since)
+				return err
+			})
+			return days, err
+		},
+		TopReferrers: func(articleID string, limit int) ([]ReferrerViews, error) {
+			var refs []ReferrerViews
+			err := around("top_referrers", func() error {
+				var err error
+				refs, err = repo.TopReferrers(articleID, limit)
+				return err
+			})
+			return refs, err
+		},
+		Ping: func() error {
+			return around("ping", repo.Ping)
+		},
+		// stats cuma baca angka di memori, close harus selalu jalan, dua-duanya ga dibungkus
+		Stats: repo.Stats,
+		Close: repo.Close,
+	}
+}
+
+// iterationerror, error operasi each* yang ga boleh diperlakuin kayak error database biasa
+// callback true: asalnya dari fn (misal client putus), database-nya sendiri baik-baik aja
+// callback false: error database setelah fn sempet jalan, kalo diulang fn bakal dapet artikel dobel
+// cuma idup di dalem around, sebelum balik ke pemanggil udah dibuka lagi
+type iterationError struct {
+	err      error
+	callback bool
+}
+
+func (e *iterationError) Error() string { return e.err.Error() }
+func (e *iterationError) Unwrap() error { return e.err }
+
+// aroundeach, around buat operasi each*: error dari fn sama error setelah fn jalan ditandain iterationerror
+// tiap lapisan decorator nandain sendiri terus buka tandanya sendiri, jadi bisa ditumpuk
+func aroundEach(around aroundFunc, op string, fn func(models.Article) error, each func(func(models.Article) error) error) error {
+	err := around(op, func() error {
+		started := false
+		err := each(func(a models.Article) error {
+			started = true
+			if err := fn(a); err != nil {
+				return &iterationError{err: err, callback: true}
+			}
+			return nil
+		})
+		var ie *iterationError
+		if err != nil && started && !errors.As(err, &ie) {
+			return &iterationError{err: err}
+		}
+		return err
+	})
+	var ie *iterationError
+	if errors.As(err, &ie) {
+		return ie.err
+	}
+	return err
+}
+
+// iscallbackerror, error-nya dari fn operasi each*, bukan dari database
+func isCallbackError(err error) bool {
+	var ie *iterationError
+	return errors.As(err, &ie) && ie.callback
+}
+
+// instrument, bungkus repository biar tiap operasi dilaporin ke observe
+// contoh higher-order function: nerima repository + function, return repository baru
+func Instrument(repo Repository, observe ObserveFunc) Repository {
+	return wrapAll(repo, func(op string, call func() error) error {
+		start := time.Now()
+		err := call()
+		// fn yang gagal ga bikin operasi database-nya dianggap error
+		if isCallbackError(err) {
+			observe(op, time.Since(start), nil)
+		} else {
+			observe(op, time.Since(start), err)
+		}
+		return err
+	})
+}
@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// newmemoryrepo, repository yang nyimpen artikel di map (buat test dan demo)
+// semantiknya dibikin sama persis kayak newsqliterepo, dicek lewat testconformance
+// state (map + mutex) disimpan dalam closure, sama kayak db connection di sqlite
+func NewMemoryRepo() Repository {
+	var mu sync.RWMutex
+	articles := map[string]models.Article{}
+	// statistik tayangan, key-nya id artikel terus hari (statsday) atau domain referrer
+	daily := map[string]map[string]int{}
+	referrers := map[string]map[string]int{}
+
+	// clone, copy artikel termasuk pointer deletedat biar caller ga bisa ngubah isi map
+	clone := func(a models.Article) models.Article {
+		if a.DeletedAt != nil {
+			t := *a.DeletedAt
+			a.DeletedAt = &t
+		}
+		return a
+	}
+
+	// byowner, artikel aktif milik owner, yang terbaru duluan
+	byOwner := func(ownerID string) []models.Article {
+		mu.RLock()
+		var result []models.Article
+		for _, a := range articles {
+			if a.OwnerID == ownerID && a.DeletedAt == nil {
+				result = append(result, clone(a))
+			}
+		}
+		mu.RUnlock()
+
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		})
+		return result
+	}
+
+	return Repository{
+		// create, id yang udah ada (termasuk yang soft deleted) ditolak kayak primary key
+		Create: func(a models.Article) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, exists := articles[a.ID]; exists {
+				return fmt.Errorf("article %s already exists", a.ID)
+			}
+			stored := clone(a)
+			stored.DeletedAt = nil // sama kayak insert sqlite yang ga nyimpen deleted_at
+			articles[a.ID] = stored
+			return nil
+		},
+
+		// get, artikel yang udah dihapus dianggap ga ada
+		Get: func(id string) (models.Article, error) {
+			mu.RLock()
+			defer mu.RUnlock()
+			a, ok := articles[id]
+			if !ok || a.DeletedAt != nil {
+				return models.Article{}, ErrNotFound
+			}
+			return clone(a), nil
+		},
+
+		// ownerof, artikel yang udah dihapus tetep keitung, sama kayak baris sqlite yang cuma ditandain
+		OwnerOf: func(id string) (string, bool, error) {
+			mu.RLock()
+			defer mu.RUnlock()
+			a, ok := articles[id]
+			if !ok {
+				return "", false, ErrNotFound
+			}
+			return a.OwnerID, a.DeletedAt != nil, nil
+		},
+
+		// update, cuma kalo id dan owner cocok, field yang diubah sama kayak query sqlite
+		Update: func(a models.Article) error {
+			mu.Lock()
+			defer mu.Unlock()
+			existing, ok := articles[a.ID]
+			if !ok || existing.OwnerID != a.OwnerID {
+				return ErrNotFound
+			}
+			existing.Title = a.Title
+			existing.Author = a.Author
+			existing.Content = a.Content
+			existing.UpdatedAt = a.UpdatedAt
+			existing.WordCount = a.WordCount
+			existing.ReadingMinutes = a.ReadingMinutes
+			existing.Excerpt = a.Excerpt
+			existing.Theme = a.Theme
+			articles[a.ID] = existing
+			return nil
+		},
+
+		// delete, soft delete, hapus dua kali dianggap not found
+		Delete: func(id string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			a, ok := articles[id]
+			if !ok || a.DeletedAt != nil {
+				return ErrNotFound
+			}
+			now := time.Now().UTC()
+			a.DeletedAt = &now
+			articles[id] = a
+			return nil
+		},
+
+		// listbyowner, artikel aktif milik owner, yang terbaru duluan
+		// content dikosongin kayak sqlite yang ga ngambil kolom content di sini
+		ListByOwner: func(ownerID string) ([]models.Article, error) {
+			list := byOwner(ownerID)
+			for i := range list {
+				list[i].Content = ""
+			}
+			return list, nil
+		},
+
+		// eachbyowner, di memori datanya udah ada semua, jadi cukup loop hasil byowner
+		EachByOwner: func(ownerID string, fn func(models.Article) error) error {
+			for _, a := range byOwner(ownerID) {
+				if err := fn(a); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+
+		// eachpublic, artikel aktif semua owner, urut dibuat paling lama duluan (lalu id)
+		EachPublic: func(offset, limit int, fn func(models.Article) error) error {
+			mu.RLock()
+			var active []models.Article
+			for _, a := range articles {
+				if a.DeletedAt == nil {
+					active = append(active, models.Article{ID: a.ID, CreatedAt: a.CreatedAt, UpdatedAt: a.UpdatedAt})
+				}
+			}
+			mu.RUnlock()
+
+			sort.Slice(active, func(i, j int) bool {
+				if !active[i].CreatedAt.Equal(active[j].CreatedAt) {
+					return active[i].CreatedAt.Before(active[j].CreatedAt)
+				}
+				return active[i].ID < active[j].ID
+			})
+			for i := offset; i < len(active) && i < offset+limit; i++ {
+				if err := fn(active[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+
+		// publicstats, jumlah artikel aktif sama updatedat paling baru
+		PublicStats: func() (PublicStats, error) {
+			mu.RLock()
+			defer mu.RUnlock()
+			var st PublicStats
+			for _, a := range articles {
+				if a.DeletedAt != nil {
+					continue
+				}
+				st.Count++
+				if a.UpdatedAt.After(st.LastUpdated) {
+					st.LastUpdated = a.UpdatedAt
+				}
+			}
+			return st, nil
+		},
+
+		// incrementviews, tambah views artikel yang masih aktif
+		IncrementViews: func(id string, n int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			a, ok := articles[id]
+			if !ok || a.DeletedAt != nil {
+				return ErrNotFound
+			}
+			a.Views += n
+			articles[id] = a
+			return nil
+		},
+
+		// recordviewstats, tambah angka harian sama referrer artikel yang masih aktif
+		RecordViewStats: func(id string, at time.Time, referrer string, n int) error {
+			mu.Lock()
+			defer mu.Unlock()
+			a, ok := articles[id]
+			if !ok || a.DeletedAt != nil {
+				return ErrNotFound
+			}
+			if daily[id] == nil {
+				daily[id] = map[string]int{}
+			}
+			daily[id][statsDay(at)] += n
+			if referrer != "" {
+				if referrers[id] == nil {
+					referrers[id] = map[string]int{}
+				}
+				if _, ok := referrers[id][referrer]; !ok {
+					domains := len(referrers[id])
+					if _, ok := referrers[id][ReferrerOther]; ok {
+						domains--
+					}
+					if domains >= MaxReferrers {
+						referrer = ReferrerOther
+					}
+				}
+				referrers[id][referrer] += n
+			}
+			return nil
+		},
+
+		// dailyviews, urut per hari terus id artikel kayak order by di sqlite
+		DailyViews: func(ownerID, articleID string, since time.Time) ([]DailyViews, error) {
+			from := statsDay(since)
+			mu.RLock()
+			var result []DailyViews
+			for id, days := range daily {
+				a := articles[id]
+				if a.OwnerID != ownerID || a.DeletedAt != nil || (articleID != "" && id != articleID) {
+					continue
+				}
+				for day, views := range days {
+					if day < from {
+						continue
+					}
+					t, _ := time.Parse("2006-01-02", day)
+					result = append(result, DailyViews{ArticleID: id, Day: t, Views: views})
+				}
+			}
+			mu.RUnlock()
+
+			sort.Slice(result, func(i, j int) bool {
+				if !result[i].Day.Equal(result[j].Day) {
+					return result[i].Day.Before(result[j].Day)
+				}
+				return result[i].ArticleID < result[j].ArticleID
+			})
+			return result, nil
+		},
+
+		// topreferrers, views terbanyak duluan, kalo sama urut nama domain
+		TopReferrers: func(articleID string, limit int) ([]ReferrerViews, error) {
+			mu.RLock()
+			var result []ReferrerViews
+			if a, ok := articles[articleID]; ok && a.DeletedAt == nil {
+				for domain, views := range referrers[articleID] {
+					result = append(result, ReferrerViews{Domain: domain, Views: views})
+				}
+			}
+			mu.RUnlock()
+
+			sort.Slice(result, func(i, j int) bool {
+				if result[i].Views != result[j].Views {
+					return result[i].Views > result[j].Views
+				}
+				return result[i].Domain < result[j].Domain
+			})
+			if limit >= 0 && len(result) > limit {
+				result = result[:limit]
+			}
+			return result, nil
+		},
+
+		// ping, memori selalu siap
+		Ping: func() error { return nil },
+
+		// stats, ga ada koneksi, schema selalu dianggap versi terbaru
+		Stats: func() Stats {
+			return Stats{SchemaVersion: len(migrations)}
+		},
+
+		// close, ga ada resource yang perlu dilepas
+		Close: func() error { return nil },
+	}
+}
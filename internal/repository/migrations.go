@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// migrations, daftar perubahan schema berurutan
+// versi schema disimpan di pragma user_version, jadi migration ke-n cuma jalan sekali
+// jangan pernah ubah migration lama, selalu tambahin yang baru di belakang
+var migrations = []string{
+	// 1: tabel artikel awal (if not exists biar database lama tetep aman)
+	`CREATE TABLE IF NOT EXISTS articles (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL,
+		content TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		views INTEGER DEFAULT 0,
+		owner_id TEXT NOT NULL,
+		deleted_at DATETIME
+	)`,
+	// 2: index buat halaman artikel saya
+	`CREATE INDEX IF NOT EXISTS idx_articles_owner ON articles (owner_id, created_at)`,
+	// 3: metadata baca (jumlah kata, menit baca, cuplikan)
+	// baris lama diisi perkiraan kasar dari sql, angka pastinya lewat dbview recompute-metadata
+	`ALTER TABLE articles ADD COLUMN word_count INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE articles ADD COLUMN reading_minutes INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE articles ADD COLUMN excerpt TEXT NOT NULL DEFAULT '';
+	UPDATE articles SET
+		excerpt = substr(trim(replace(content, '<br>', ' ')), 1, 160),
+		word_count = CASE WHEN trim(replace(content, '<br>', ' ')) = '' THEN 0 ELSE
+			length(trim(replace(content, '<br>', ' '))) - length(replace(trim(replace(content, '<br>', ' ')), ' ', '')) + 1 END;
+	UPDATE articles SET reading_minutes = CASE WHEN word_count = 0 THEN 0 ELSE (word_count + 199) / 200 END`,
+	// 4: saran tema baca per artikel
+	`ALTER TABLE articles ADD COLUMN theme TEXT NOT NULL DEFAULT ''`,
+	// 5: statistik tayangan, cuma angka per hari sama per domain referrer (tanpa ip)
+	`CREATE TABLE article_daily_views (
+		article_id TEXT NOT NULL,
+		day TEXT NOT NULL,
+		views INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (article_id, day)
+	);
+	CREATE TABLE article_referrers (
+		article_id TEXT NOT NULL,
+		domain TEXT NOT NULL,
+		views INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (article_id, domain)
+	)`,
+}
+
+// schemaversion, versi schema yang sekarang ada di database
+func schemaVersion(db *sql.DB) (int, error) {
+	var v int
+	err := db.QueryRow(`PRAGMA user_version`).Scan(&v)
+	return v, err
+}
+
+// checkschema, pastiin schema database sama persis sama versi aplikasi tanpa ngubah apa-apa
+// buat alat yang buka database langsung (misal admin), migration tetep cuma dijalanin server
+func CheckSchema(db *sql.DB) error {
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	switch {
+	case current < len(migrations):
+		return fmt.Errorf("schema version %d lebih lama dari aplikasi (%d), jalanin server versi ini sekali buat migrasi", current, len(migrations))
+	case current > len(migrations):
+		return fmt.Errorf("schema version %d lebih baru dari aplikasi (%d)", current, len(migrations))
+	}
+	return nil
+}
+
+// migrate, jalanin semua migration yang belum diterapkan
+// tiap migration dibungkus transaksi bareng update user_version-nya
+func migrate(db *sql.DB) error {
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("schema version %d lebih baru dari aplikasi (%d)", current, len(migrations))
+	}
+	for i := current; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		// pragma ga bisa pake placeholder, jadi angka di-format langsung
+		if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("migration %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
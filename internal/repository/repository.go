@@ -2,6 +2,8 @@ package repository
 
 import (
 	"errors"
+	"time"
+
 	"github.com/fhmptrdnd/private-blog/internal/models"
 )
 
@@ -14,7 +16,12 @@ type CreateFunc func(models.Article) error
 // getfunc, function type buat get artikel
 type GetFunc func(string) (models.Article, error)
 
+// owneroffunc, function type buat cek siapa pemilik id, termasuk artikel yang udah dihapus
+// errnotfound cuma kalo id-nya belum pernah dipake sama sekali, dipake import biar ga nabrak primary key
+type OwnerOfFunc func(id string) (ownerID string, deleted bool, err error)
+
 // updatefunc, function type buat update artikel
+// views ga ikut ditulis, angka itu cuma berubah lewat incrementviews
 type UpdateFunc func(models.Article) error
 
 // deletefunc, function type buat delete artikel
@@ -23,12 +30,105 @@ type DeleteFunc func(string) error
 // listbyownerfunc, function type buat list artikel by owner
 type ListByOwnerFunc func(ownerID string) ([]models.Article, error)
 
+// eachbyownerfunc, function type buat jalanin fn ke tiap artikel milik owner satu per satu
+// beda sama listbyowner, ga ngumpulin semua ke memori (buat export dll)
+// kalo fn return error, iterasi berhenti dan error-nya dibalikin
+type EachByOwnerFunc func(ownerID string, fn func(models.Article) error) error
+
+// eachpublicfunc, function type buat jalanin fn ke semua artikel aktif (semua owner), per halaman
+// cuma id, createdat, sama updatedat yang diisi, cukup buat sitemap tanpa bawa content
+// urutannya tetap (dibuat paling lama duluan) biar offset antar halaman konsisten
+type EachPublicFunc func(offset, limit int, fn func(models.Article) error) error
+
+// publicstats, ringkasan artikel aktif
+type PublicStats struct {
+	Count       int
+	LastUpdated time.Time // updatedat paling baru, zero kalo belum ada artikel
+}
+
+// publicstatsfunc, function type buat ambil publicstats
+type PublicStatsFunc func() (PublicStats, error)
+
+// incrementviewsfunc, function type buat nambah views artikel secara atomik
+type IncrementViewsFunc func(id string, n int) error
+
+// dailyviews, tayangan satu artikel di satu hari (utc)
+type DailyViews struct {
+	ArticleID string
+	Day       time.Time // jam 00:00 utc
+	Views     int
+}
+
+// referrerviews, tayangan yang datang dari satu domain
+type ReferrerViews struct {
+	Domain string
+	Views  int
+}
+
+// maxreferrers, batas domain yang dicatet per artikel
+// referer bisa diisi apa aja sama pengunjung, jadi domain ke-51 dan seterusnya digabung ke referrerother
+const MaxReferrers = 50
+
+// referrerother, domain gabungan buat referrer di luar maxreferrers, bukan hostname yang valid jadi ga bakal bentrok
+const ReferrerOther = "*"
+
+// other, baris ini gabungan domain lain-lain
+func (r ReferrerViews) Other() bool { return r.Domain == ReferrerOther }
+
+// recordviewstatsfunc, function type buat nambah agregat harian artikel aktif (sama domain referrer kalo ada)
+// yang disimpen cuma angka per hari sama per domain, ga ada ip atau data pengunjung lain
+// domain baru di luar maxreferrers per artikel dicatet sebagai referrerother
+type RecordViewStatsFunc func(id string, at time.Time, referrer string, n int) error
+
+// dailyviewsfunc, function type buat tayangan harian artikel aktif milik owner sejak hari since
+// articleid kosong = semua artikel owner. urut hari terus id artikel, hari tanpa tayangan ga ada barisnya
+type DailyViewsFunc func(ownerID, articleID string, since time.Time) ([]DailyViews, error)
+
+// topreferrersfunc, function type buat domain referrer terbanyak satu artikel, paling rame duluan
+type TopReferrersFunc func(articleID string, limit int) ([]ReferrerViews, error)
+
+// statsday, hari (utc) buat kunci agregat harian, format teks biar urutannya ikut urutan string
+func statsDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// pingfunc, function type buat cek database siap dipake
+type PingFunc func() error
+
+// stats, info runtime dari backend penyimpanan
+type Stats struct {
+	OpenConnections int
+	InUse           int
+	Idle            int
+	SchemaVersion   int
+}
+
+// statsfunc, function type buat ambil stats backend
+type StatsFunc func() Stats
+
+// closefunc, function type buat lepas resource (koneksi, statement, goroutine)
+type CloseFunc func() error
+
 // repository, struct yang isinya function-function (bukan interface!)
 // ini penerapan "functions as first-class citizens" di layer data
 type Repository struct {
-	Create      CreateFunc
-	Get         GetFunc
-	Update      UpdateFunc
-	Delete      DeleteFunc
-	ListByOwner ListByOwnerFunc
+	Create         CreateFunc
+	Get            GetFunc
+	OwnerOf        OwnerOfFunc
+	Update         UpdateFunc
+	Delete         DeleteFunc
+	ListByOwner    ListByOwnerFunc
+	EachByOwner    EachByOwnerFunc
+	EachPublic     EachPublicFunc
+	PublicStats    PublicStatsFunc
+	IncrementViews IncrementViewsFunc
+
+	// statistik tayangan per hari sama per referrer
+	RecordViewStats RecordViewStatsFunc
+	DailyViews      DailyViewsFunc
+	TopReferrers    TopReferrersFunc
+
+	Ping  PingFunc
+	Stats StatsFunc
+	Close CloseFunc
 }
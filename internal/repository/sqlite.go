@@ -2,66 +2,276 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/fhmptrdnd/private-blog/internal/models"
 	_ "modernc.org/sqlite"
 )
 
-// return repository struct yang isinya function-function
-// state (db connection) disimpan dalam closure
+// sqliteoptions, setting koneksi sqlite
+type SQLiteOptions struct {
+	BusyTimeout  time.Duration // berapa lama nunggu lock sebelum sqlite_busy
+	Synchronous  string        // OFF, NORMAL, FULL, EXTRA
+	MaxReadConns int           // ukuran pool buat query baca
+}
+
+// defaultsqliteoptions, setting yang aman buat server dengan banyak request barengan
+func DefaultSQLiteOptions() SQLiteOptions {
+	return SQLiteOptions{
+		BusyTimeout:  5 * time.Second,
+		Synchronous:  "NORMAL", // aman di mode wal, jauh lebih cepet dari full
+		MaxReadConns: 4,
+	}
+}
+
+// sqlitedsn, tambahin pragma ke path database
+// pragma diterapin driver di tiap koneksi baru, jadi semua koneksi di pool seragam
+func sqliteDSN(dbPath string, opts SQLiteOptions, immediate bool) string {
+	q := url.Values{}
+	q.Add("_pragma", "journal_mode(WAL)")
+	q.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", opts.BusyTimeout.Milliseconds()))
+	q.Add("_pragma", fmt.Sprintf("synchronous(%s)", opts.Synchronous))
+	q.Add("_pragma", "foreign_keys(1)")
+	if immediate {
+		// transaksi langsung ambil write lock, biar ga deadlock pas upgrade dari read lock
+		q.Set("_txlock", "immediate")
+	}
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	return dbPath + sep + q.Encode()
+}
+
+// newsqliterepo, bikin repository sqlite dengan setting default
 func NewSQLiteRepo(dbPath string) (Repository, error) {
-	// open database connection
-	db, err := sql.Open("sqlite", dbPath)
+	return NewSQLiteRepoWithOptions(dbPath, DefaultSQLiteOptions())
+}
+
+// return repository struct yang isinya function-function
+// state (db connection + prepared statement) disimpan dalam closure
+func NewSQLiteRepoWithOptions(dbPath string, opts SQLiteOptions) (Repository, error) {
+	// jalanin migration schema (termasuk bikin tabel kalo belum ada)
+	return newSQLiteRepo(dbPath, opts, migrate)
+}
+
+// opensqliterepo, buka database yang udah ada tanpa migration, buat alat operator (dbview)
+// path yang salah jadi error (bukan bikin database kosong), schema harus udah sama kayak versi aplikasi
+func OpenSQLiteRepo(dbPath string) (Repository, error) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return Repository{}, err
+	}
+	return newSQLiteRepo(dbPath, DefaultSQLiteOptions(), CheckSchema)
+}
+
+// newsqliterepo, isi newsqliterepowithoptions/opensqliterepo
+// schema dipanggil sekali di koneksi writer sebelum statement di-prepare
+func newSQLiteRepo(dbPath string, opts SQLiteOptions, schema func(*sql.DB) error) (Repository, error) {
+	if opts.MaxReadConns < 1 {
+		opts.MaxReadConns = 1
+	}
+
+	// writer cuma satu koneksi: sqlite emang cuma bisa satu penulis,
+	// jadi mending antri di pool daripada rebutan lock dan dapet "database is locked"
+	writer, err := sql.Open("sqlite", sqliteDSN(dbPath, opts, true))
 	if err != nil {
 		return Repository{}, err
 	}
+	writer.SetMaxOpenConns(1)
 
 	// test connection
-	if err := db.Ping(); err != nil {
+	if err := writer.Ping(); err != nil {
+		writer.Close()
 		return Repository{}, err
 	}
 
-	// create table kalo belum ada
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS articles (
-		id TEXT PRIMARY KEY,
-		title TEXT NOT NULL,
-		author TEXT NOT NULL,
-		content TEXT NOT NULL,
-		created_at DATETIME NOT NULL,
-		updated_at DATETIME NOT NULL,
-		views INTEGER DEFAULT 0,
-		owner_id TEXT NOT NULL,
-		deleted_at DATETIME
-	);
-	`
-	if _, err := db.Exec(createTableSQL); err != nil {
+	if err := schema(writer); err != nil {
+		writer.Close()
 		return Repository{}, err
 	}
 
-	// return repository dengan closures yang capture db connection
+	// reader boleh banyak karena wal ngizinin baca barengan sama nulis
+	// database :memory: beda per koneksi, jadi di situ reader = writer
+	reader := writer
+	if dbPath != ":memory:" {
+		reader, err = sql.Open("sqlite", sqliteDSN(dbPath, opts, false))
+		if err != nil {
+			writer.Close()
+			return Repository{}, err
+		}
+		reader.SetMaxOpenConns(opts.MaxReadConns)
+		reader.SetMaxIdleConns(opts.MaxReadConns)
+	}
+
+	// closeall, tutup semua statement yang udah di-prepare terus koneksinya
+	var stmts []*sql.Stmt
+	closeAll := func() error {
+		var errs []error
+		for _, st := range stmts {
+			errs = append(errs, st.Close())
+		}
+		if reader != writer {
+			errs = append(errs, reader.Close())
+		}
+		errs = append(errs, writer.Close())
+		return errors.Join(errs...)
+	}
+
+	// prepare, siapin query sekali di awal, dipake ulang di tiap pemanggilan
+	var prepErr error
+	prepare := func(db *sql.DB, query string) *sql.Stmt {
+		if prepErr != nil {
+			return nil
+		}
+		st, err := db.Prepare(query)
+		if err != nil {
+			prepErr = fmt.Errorf("prepare %q: %w", strings.Join(strings.Fields(query), " "), err)
+			return nil
+		}
+		stmts = append(stmts, st)
+		return st
+	}
+
+	insertStmt := prepare(writer, `
+		INSERT INTO articles (id, title, author, content, created_at, updated_at, views, owner_id,
+			word_count, reading_minutes, excerpt, theme)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	getStmt := prepare(reader, `
+		SELECT id, title, author, content, created_at, updated_at, views, owner_id, deleted_at,
+			word_count, reading_minutes, excerpt, theme
+		FROM articles
+		WHERE id = ? AND deleted_at IS NULL
+	`)
+	ownerOfStmt := prepare(reader, `SELECT owner_id, deleted_at IS NOT NULL FROM articles WHERE id = ?`)
+	updateStmt := prepare(writer, `
+		UPDATE articles
+		SET title = ?, author = ?, content = ?, updated_at = ?,
+			word_count = ?, reading_minutes = ?, excerpt = ?, theme = ?
+		WHERE id = ? AND owner_id = ?
+	`)
+	deleteStmt := prepare(writer, `UPDATE articles SET deleted_at = datetime('now') WHERE id = ? AND deleted_at IS NULL`)
+	eachByOwnerStmt := prepare(reader, `
+		SELECT id, title, author, content, created_at, updated_at, views, owner_id, deleted_at,
+			word_count, reading_minutes, excerpt, theme
+		FROM articles
+		WHERE owner_id = ? AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`)
+	// listbyowner sengaja ga ambil content, halaman daftar cukup pake metadata
+	listByOwnerStmt := prepare(reader, `
+		SELECT id, title, author, created_at, updated_at, views, owner_id, deleted_at,
+			word_count, reading_minutes, excerpt, theme
+		FROM articles
+		WHERE owner_id = ? AND deleted_at IS NULL
+		ORDER BY created_at DESC
+	`)
+	// id ikut diurutin biar halaman sitemap stabil kalo created_at-nya sama
+	eachPublicStmt := prepare(reader, `
+		SELECT id, created_at, updated_at
+		FROM articles
+		WHERE deleted_at IS NULL
+		ORDER BY created_at, id
+		LIMIT ? OFFSET ?
+	`)
+	countPublicStmt := prepare(reader, `SELECT COUNT(*) FROM articles WHERE deleted_at IS NULL`)
+	lastUpdatedStmt := prepare(reader, `
+		SELECT updated_at
+		FROM articles
+		WHERE deleted_at IS NULL
+		ORDER BY updated_at DESC
+		LIMIT 1
+	`)
+	incrementViewsStmt := prepare(writer, `UPDATE articles SET views = views + ? WHERE id = ? AND deleted_at IS NULL`)
+	// insert ... select biar artikel yang ga ada atau udah dihapus ga dapet baris (jadi errnotfound)
+	// where di select wajib ada biar on conflict-nya ga dibaca sebagai join
+	recordDailyStmt := prepare(writer, `
+		INSERT INTO article_daily_views (article_id, day, views)
+		SELECT id, ?, ? FROM articles WHERE id = ? AND deleted_at IS NULL
+		ON CONFLICT (article_id, day) DO UPDATE SET views = views + excluded.views
+	`)
+	recordReferrerStmt := prepare(writer, `
+		INSERT INTO article_referrers (article_id, domain, views)
+		SELECT id, ?, ? FROM articles WHERE id = ? AND deleted_at IS NULL
+		ON CONFLICT (article_id, domain) DO UPDATE SET views = views + excluded.views
+	`)
+	bumpReferrerStmt := prepare(writer, `UPDATE article_referrers SET views = views + ? WHERE article_id = ? AND domain = ?`)
+	countReferrersStmt := prepare(writer, `SELECT COUNT(*) FROM article_referrers WHERE article_id = ? AND domain <> ?`)
+	dailyViewsStmt := prepare(reader, `
+		SELECT d.article_id, d.day, d.views
+		FROM article_daily_views d
+		JOIN articles a ON a.id = d.article_id
+		WHERE a.owner_id = ? AND a.deleted_at IS NULL AND d.day >= ? AND (? = '' OR d.article_id = ?)
+		ORDER BY d.day, d.article_id
+	`)
+	topReferrersStmt := prepare(reader, `
+		SELECT r.domain, r.views
+		FROM article_referrers r
+		JOIN articles a ON a.id = r.article_id
+		WHERE r.article_id = ? AND a.deleted_at IS NULL
+		ORDER BY r.views DESC, r.domain
+		LIMIT ?
+	`)
+	if prepErr != nil {
+		closeAll()
+		return Repository{}, prepErr
+	}
+
+	// eachrow, jalanin fn ke tiap baris hasil query, langsung dari cursor
+	// scan dikasih dari luar karena kolom tiap query beda
+	eachRow := func(st *sql.Stmt, scan func(*sql.Rows) (models.Article, error), fn func(models.Article) error, args ...any) error {
+		rows, err := st.Query(args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			a, err := scan(rows)
+			if err != nil {
+				return err
+			}
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+
+	// eachbyowner, jalanin fn ke tiap artikel aktif milik owner (lengkap sama content)
+	eachByOwner := func(ownerID string, fn func(models.Article) error) error {
+		return eachRow(eachByOwnerStmt, func(rows *sql.Rows) (models.Article, error) {
+			var a models.Article
+			err := rows.Scan(
+				&a.ID, &a.Title, &a.Author, &a.Content,
+				&a.CreatedAt, &a.UpdatedAt, &a.Views, &a.OwnerID, &a.DeletedAt,
+				&a.WordCount, &a.ReadingMinutes, &a.Excerpt, &a.Theme,
+			)
+			return a, err
+		}, fn, ownerID)
+	}
+
+	// return repository dengan closures yang capture statement
 	return Repository{
 		// create, insert artikel baru
 		Create: func(a models.Article) error {
-			query := `
-				INSERT INTO articles (id, title, author, content, created_at, updated_at, views, owner_id)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-			`
-			_, err := db.Exec(query, a.ID, a.Title, a.Author, a.Content, a.CreatedAt, a.UpdatedAt, a.Views, a.OwnerID)
+			_, err := insertStmt.Exec(a.ID, a.Title, a.Author, a.Content, a.CreatedAt, a.UpdatedAt, a.Views, a.OwnerID,
+				a.WordCount, a.ReadingMinutes, a.Excerpt, a.Theme)
 			return err
 		},
 
 		// get, ambil artikel by id
 		Get: func(id string) (models.Article, error) {
-			query := `
-				SELECT id, title, author, content, created_at, updated_at, views, owner_id, deleted_at
-				FROM articles
-				WHERE id = ? AND deleted_at IS NULL
-			`
 			var a models.Article
-			err := db.QueryRow(query, id).Scan(
+			err := getStmt.QueryRow(id).Scan(
 				&a.ID, &a.Title, &a.Author, &a.Content,
 				&a.CreatedAt, &a.UpdatedAt, &a.Views, &a.OwnerID, &a.DeletedAt,
+				&a.WordCount, &a.ReadingMinutes, &a.Excerpt, &a.Theme,
 			)
 			if err == sql.ErrNoRows {
 				return models.Article{}, ErrNotFound
@@ -72,74 +282,206 @@ func NewSQLiteRepo(dbPath string) (Repository, error) {
 			return a, nil
 		},
 
+		// ownerof, pemilik id termasuk yang udah dihapus
+		OwnerOf: func(id string) (string, bool, error) {
+			var owner string
+			var deleted bool
+			err := ownerOfStmt.QueryRow(id).Scan(&owner, &deleted)
+			if err == sql.ErrNoRows {
+				return "", false, ErrNotFound
+			}
+			if err != nil {
+				return "", false, err
+			}
+			return owner, deleted, nil
+		},
+
 		// update, update artikel yang ada
 		Update: func(a models.Article) error {
-			query := `
-				UPDATE articles
-				SET title = ?, author = ?, content = ?, updated_at = ?, views = ?
-				WHERE id = ? AND owner_id = ?
-			`
-			result, err := db.Exec(query, a.Title, a.Author, a.Content, a.UpdatedAt, a.Views, a.ID, a.OwnerID)
+			result, err := updateStmt.Exec(a.Title, a.Author, a.Content, a.UpdatedAt,
+				a.WordCount, a.ReadingMinutes, a.Excerpt, a.Theme, a.ID, a.OwnerID)
+			return expectAffected(result, err)
+		},
+
+		// delete, soft delete artikel (set deleted_at)
+		Delete: func(id string) error {
+			return expectAffected(deleteStmt.Exec(id))
+		},
+
+		// listbyowner, ambil semua artikel milik user tertentu (tanpa content)
+		ListByOwner: func(ownerID string) ([]models.Article, error) {
+			var articles []models.Article
+			err := eachRow(listByOwnerStmt, func(rows *sql.Rows) (models.Article, error) {
+				var a models.Article
+				err := rows.Scan(
+					&a.ID, &a.Title, &a.Author,
+					&a.CreatedAt, &a.UpdatedAt, &a.Views, &a.OwnerID, &a.DeletedAt,
+					&a.WordCount, &a.ReadingMinutes, &a.Excerpt, &a.Theme,
+				)
+				return a, err
+			}, func(a models.Article) error {
+				articles = append(articles, a)
+				return nil
+			}, ownerID)
 			if err != nil {
-				return err
+				return nil, err
 			}
-			rows, err := result.RowsAffected()
-			if err != nil {
-				return err
+			return articles, nil
+		},
+
+		// eachbyowner, sama kayak listbyowner tapi baris dikirim satu-satu ke fn
+		EachByOwner: eachByOwner,
+
+		// eachpublic, artikel aktif semua owner, cuma kolom yang dibutuhin sitemap
+		EachPublic: func(offset, limit int, fn func(models.Article) error) error {
+			return eachRow(eachPublicStmt, func(rows *sql.Rows) (models.Article, error) {
+				var a models.Article
+				err := rows.Scan(&a.ID, &a.CreatedAt, &a.UpdatedAt)
+				return a, err
+			}, fn, limit, offset)
+		},
+
+		// publicstats, jumlah artikel aktif sama kapan terakhir ada yang berubah
+		PublicStats: func() (PublicStats, error) {
+			var st PublicStats
+			if err := countPublicStmt.QueryRow().Scan(&st.Count); err != nil {
+				return PublicStats{}, err
 			}
-			if rows == 0 {
-				return ErrNotFound
+			err := lastUpdatedStmt.QueryRow().Scan(&st.LastUpdated)
+			if err != nil && err != sql.ErrNoRows {
+				return PublicStats{}, err
 			}
-			return nil
+			return st, nil
 		},
 
-		// delete, soft delete artikel (set deleted_at)
-		Delete: func(id string) error {
-			query := `UPDATE articles SET deleted_at = datetime('now') WHERE id = ? AND deleted_at IS NULL`
-			result, err := db.Exec(query, id)
+		// incrementviews, tambah views langsung di database
+		// satu query atomik, jadi ga ada race kayak get lalu update
+		IncrementViews: func(id string, n int) error {
+			return expectAffected(incrementViewsStmt.Exec(n, id))
+		},
+
+		// recordviewstats, tambah angka harian sama referrer dalam satu transaksi
+		RecordViewStats: func(id string, at time.Time, referrer string, n int) error {
+			tx, err := writer.Begin()
 			if err != nil {
 				return err
 			}
-			rows, err := result.RowsAffected()
-			if err != nil {
+			defer tx.Rollback()
+			if err := expectAffected(tx.Stmt(recordDailyStmt).Exec(statsDay(at), n, id)); err != nil {
 				return err
 			}
-			if rows == 0 {
-				return ErrNotFound
+			if referrer != "" {
+				// domain yang udah kecatet tinggal ditambah, domain baru dicek dulu jatahnya
+				result, err := tx.Stmt(bumpReferrerStmt).Exec(n, id, referrer)
+				if err != nil {
+					return err
+				}
+				if bumped, err := result.RowsAffected(); err != nil {
+					return err
+				} else if bumped == 0 {
+					var domains int
+					if err := tx.Stmt(countReferrersStmt).QueryRow(id, ReferrerOther).Scan(&domains); err != nil {
+						return err
+					}
+					if domains >= MaxReferrers {
+						referrer = ReferrerOther
+					}
+					if _, err := tx.Stmt(recordReferrerStmt).Exec(referrer, n, id); err != nil {
+						return err
+					}
+				}
 			}
-			return nil
+			return tx.Commit()
 		},
 
-		// listbyowner, ambil semua artikel milik user tertentu
-		ListByOwner: func(ownerID string) ([]models.Article, error) {
-			query := `
-				SELECT id, title, author, content, created_at, updated_at, views, owner_id, deleted_at
-				FROM articles
-				WHERE owner_id = ? AND deleted_at IS NULL
-				ORDER BY created_at DESC
-			`
-			rows, err := db.Query(query, ownerID)
+		// dailyviews, tayangan harian artikel milik owner (bisa difilter satu artikel)
+		DailyViews: func(ownerID, articleID string, since time.Time) ([]DailyViews, error) {
+			rows, err := dailyViewsStmt.Query(ownerID, statsDay(since), articleID, articleID)
 			if err != nil {
 				return nil, err
 			}
 			defer rows.Close()
-
-			var articles []models.Article
+			var days []DailyViews
 			for rows.Next() {
-				var a models.Article
-				err := rows.Scan(
-					&a.ID, &a.Title, &a.Author, &a.Content,
-					&a.CreatedAt, &a.UpdatedAt, &a.Views, &a.OwnerID, &a.DeletedAt,
-				)
-				if err != nil {
+				var d DailyViews
+				var day string
+				if err := rows.Scan(&d.ArticleID, &day, &d.Views); err != nil {
 					return nil, err
 				}
-				articles = append(articles, a)
+				if d.Day, err = time.Parse("2006-01-02", day); err != nil {
+					return nil, err
+				}
+				days = append(days, d)
 			}
-			if err = rows.Err(); err != nil {
+			return days, rows.Err()
+		},
+
+		// topreferrers, domain asal terbanyak satu artikel
+		TopReferrers: func(articleID string, limit int) ([]ReferrerViews, error) {
+			rows, err := topReferrersStmt.Query(articleID, limit)
+			if err != nil {
 				return nil, err
 			}
-			return articles, nil
+			defer rows.Close()
+			var refs []ReferrerViews
+			for rows.Next() {
+				var r ReferrerViews
+				if err := rows.Scan(&r.Domain, &r.Views); err != nil {
+					return nil, err
+				}
+				refs = append(refs, r)
+			}
+			return refs, rows.Err()
+		},
+
+		// ping, cek koneksi database sama state migration
+		Ping: func() error {
+			if err := writer.Ping(); err != nil {
+				return err
+			}
+			if err := reader.Ping(); err != nil {
+				return err
+			}
+			v, err := schemaVersion(reader)
+			if err != nil {
+				return err
+			}
+			if v != len(migrations) {
+				return fmt.Errorf("schema version %d, expected %d", v, len(migrations))
+			}
+			return nil
+		},
+
+		// stats, info koneksi buat monitoring (writer + reader digabung)
+		Stats: func() Stats {
+			w := writer.Stats()
+			st := Stats{OpenConnections: w.OpenConnections, InUse: w.InUse, Idle: w.Idle}
+			if reader != writer {
+				r := reader.Stats()
+				st.OpenConnections += r.OpenConnections
+				st.InUse += r.InUse
+				st.Idle += r.Idle
+			}
+			st.SchemaVersion, _ = schemaVersion(reader)
+			return st
 		},
+
+		// close, lepas semua prepared statement sama koneksi
+		Close: closeAll,
 	}, nil
 }
+
+// expectaffected, ubah hasil exec jadi errnotfound kalo ga ada baris yang kena
+func expectAffected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -33,11 +33,24 @@ func NewRealIDGen() IDGenFunc {
 	}
 }
 
+// eventfunc, dipanggil tiap ada kejadian penting di domain (buat metrics dll)
+type EventFunc func(event string)
+
+// nama-nama event yang dikirim ke eventfunc
+const (
+	EventArticleCreated  = "article_created"
+	EventArticleUpdated  = "article_updated"
+	EventArticleDeleted  = "article_deleted"
+	EventViewIncremented = "view_incremented"
+)
+
 // articleservice, struct utama buat manage artikel
 type ArticleService struct {
 	repo  repository.Repository
 	clock ClockFunc // ini function, bukan interface!
 	idGen IDGenFunc
+	emit  EventFunc
+	seen  SeenFunc // dedup kunjungan buat recordview
 }
 
 // newarticleservice, bikin service baru
@@ -47,9 +60,24 @@ func NewArticleService(r repository.Repository, clock ClockFunc, idGen IDGenFunc
 		repo:  r,
 		clock: clock,
 		idGen: idGen,
+		emit:  func(string) {}, // default ga ngapa-ngapain
+		seen:  NewViewDedup(DefaultViewWindow, clock),
 	}
 }
 
+// withevents, balikin copy service yang manggil fn tiap ada event
+// service aslinya ga diubah (immutable)
+func (s *ArticleService) WithEvents(fn EventFunc) *ArticleService {
+	copied := *s
+	copied.emit = fn
+	return &copied
+}
+
+// ping, cek penyimpanan siap dipake (buat readiness check)
+func (s *ArticleService) Ping() error {
+	return s.repo.Ping()
+}
+
 // sanitizehtml, bersihin html, ganti newline jadi <br>
 // pure function: input sama = output sama, ga ada efek samping
 func sanitizeHTML(content string) string {
@@ -58,12 +86,14 @@ func sanitizeHTML(content string) string {
 	return content
 }
 
-// create, bikin artikel baru
-// return value (bukan pointer) biar immutable
-func (s *ArticleService) Create(title, author, content, ownerID string) (models.Article, error) {
+// build, validasi input terus bikin artikel baru lewat pipeline content (sanitize, opts, metadata)
+// dipake create sama preview biar hasil preview persis sama kayak yang nanti disimpen
+func (s *ArticleService) build(title, author, content, ownerID string, opts []ArticleTransform) (models.Article, error) {
+	if err := validateInput(title, author, content); err != nil {
+		return models.Article{}, err
+	}
 	now := s.clock()
-	a := models.Article{
-		ID:        s.idGen(),
+	return Pipe(models.Article{
 		Title:     title,
 		Author:    author,
 		Content:   sanitizeHTML(content),
@@ -71,13 +101,31 @@ func (s *ArticleService) Create(title, author, content, ownerID string) (models.
 		UpdatedAt: now,  // set updatedat = createdat saat create
 		Views:     0,
 		OwnerID:   ownerID,
+	}, append(opts, WithMetadata())...), nil
+}
+
+// create, bikin artikel baru
+// return value (bukan pointer) biar immutable
+// opts buat field tambahan yang ga wajib (misal withtheme), dijalanin sebelum metadata dihitung
+func (s *ArticleService) Create(title, author, content, ownerID string, opts ...ArticleTransform) (models.Article, error) {
+	a, err := s.build(title, author, content, ownerID, opts)
+	if err != nil {
+		return models.Article{}, err
 	}
+	a.ID = s.idGen()
 	if err := s.repo.Create(a); err != nil {
 		return models.Article{}, err
 	}
+	s.emit(EventArticleCreated)
 	return a, nil
 }
 
+// preview, artikel hasil pipeline yang sama persis kayak create tapi ga disimpen
+// ga punya id, ga ngirim event
+func (s *ArticleService) Preview(title, author, content string, opts ...ArticleTransform) (models.Article, error) {
+	return s.build(title, author, content, "", opts)
+}
+
 // get, ambil artikel berdasarkan id
 // cuma baca aja, ga ngubah apapun (pure query)
 func (s *ArticleService) Get(id string) (models.Article, error) {
@@ -87,18 +135,20 @@ func (s *ArticleService) Get(id string) (models.Article, error) {
 // incrementviews, nambah jumlah views artikel
 // ini ngubah state (command), beda sama get yang cuma baca
 func (s *ArticleService) IncrementViews(id string) error {
-	a, err := s.repo.Get(id)
-	if err != nil {
+	// langsung ke repository biar atomik (dan bisa di-batch sama cache)
+	if err := s.repo.IncrementViews(id, 1); err != nil {
 		return err
 	}
-	// bikin copy dulu, baru ubah (biar immutable)
-	updated := a
-	updated.Views++
-	return s.repo.Update(updated)
+	s.emit(EventViewIncremented)
+	return nil
 }
 
 // update, update artikel yang udah ada
-func (s *ArticleService) Update(id, title, author, content, ownerID string) (models.Article, error) {
+// field yang ga disebut di opts (misal tema) tetep pake nilai lama
+func (s *ArticleService) Update(id, title, author, content, ownerID string, opts ...ArticleTransform) (models.Article, error) {
+	if err := validateInput(title, author, content); err != nil {
+		return models.Article{}, err
+	}
 	a, err := s.repo.Get(id)
 	if err != nil {
 		return models.Article{}, err
@@ -113,10 +163,12 @@ func (s *ArticleService) Update(id, title, author, content, ownerID string) (mod
 	updated.Author = author
 	updated.Content = sanitizeHTML(content)
 	updated.UpdatedAt = s.clock()  // update timestamp saat update
+	updated = Pipe(updated, append(opts, WithMetadata())...)
 	
 	if err := s.repo.Update(updated); err != nil {
 		return models.Article{}, err
 	}
+	s.emit(EventArticleUpdated)
 	return updated, nil
 }
 
@@ -129,7 +181,11 @@ func (s *ArticleService) Delete(id, ownerID string) error {
 	if a.OwnerID != ownerID {
 		return repository.ErrNotFound
 	}
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+	s.emit(EventArticleDeleted)
+	return nil
 }
 
 // listmyarticles, ambil semua artikel milik user
@@ -137,3 +193,14 @@ func (s *ArticleService) Delete(id, ownerID string) error {
 func (s *ArticleService) ListMyArticles(ownerID string) ([]models.Article, error) {
 	return s.repo.ListByOwner(ownerID)
 }
+
+// publicstats, jumlah artikel publik sama kapan terakhir ada yang berubah (buat sitemap)
+func (s *ArticleService) PublicStats() (repository.PublicStats, error) {
+	return s.repo.PublicStats()
+}
+
+// eachpublic, jalanin fn buat tiap artikel publik satu per satu, ga ditampung di memori
+// artikel cuma berisi id, createdat, sama updatedat
+func (s *ArticleService) EachPublic(offset, limit int, fn func(models.Article) error) error {
+	return s.repo.EachPublic(offset, limit, fn)
+}
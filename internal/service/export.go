@@ -0,0 +1,90 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+)
+
+// manifestentry, satu baris di manifest.json
+type manifestEntry struct {
+	ID      string    `json:"id"`
+	Title   string    `json:"title"`
+	Author  string    `json:"author"`
+	File    string    `json:"file"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	Views   int       `json:"views"`
+}
+
+// manifest, isi manifest.json di dalam zip export
+// owner id sengaja ga ditulis karena itu sama aja kayak password cookie
+type manifest struct {
+	ExportedAt time.Time       `json:"exported_at"`
+	Count      int             `json:"count"`
+	Articles   []manifestEntry `json:"articles"`
+}
+
+// exportowner, tulis zip berisi semua artikel milik owner ke w
+// tiap artikel jadi satu file markdown dengan yaml front matter, ditambah manifest.json
+// yang dikumpulin duluan cuma id-nya, isi artikel diambil terus ditulis satu per satu,
+// jadi memori ga ikut gede sama jumlah artikel dan koneksi database ga ketahan selama download
+// (client lambat atau putus di tengah jalan juga ga kebaca sebagai error database)
+func (s *ArticleService) ExportOwner(ownerID string, w io.Writer) error {
+	var ids []string
+	err := s.repo.EachByOwner(ownerID, func(a models.Article) error {
+		ids = append(ids, a.ID)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	m := manifest{ExportedAt: s.clock(), Articles: []manifestEntry{}}
+	for _, id := range ids {
+		a, err := s.repo.Get(id)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue // dihapus selagi export jalan
+		}
+		if err != nil {
+			return err
+		}
+		if a.OwnerID != ownerID {
+			continue // dipindah ke owner lain selagi export jalan
+		}
+		name := "articles/" + slugify(a.Title) + "-" + a.ID + ".md"
+		f, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: a.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, toMarkdown(a)); err != nil {
+			return err
+		}
+		m.Articles = append(m.Articles, manifestEntry{
+			ID: a.ID, Title: a.Title, Author: a.Author, File: name,
+			Created: a.CreatedAt, Updated: a.UpdatedAt, Views: a.Views,
+		})
+	}
+
+	m.Count = len(m.Articles)
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.json", Method: zip.Deflate, Modified: m.ExportedAt})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+	return zw.Close()
+}
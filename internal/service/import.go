@@ -0,0 +1,302 @@
+package service
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+)
+
+// maximportfilesize, batas ukuran satu file markdown yang di-import
+const maxImportFileSize = 4 << 20
+
+// status hasil import per file
+const (
+	ImportCreated   = "created"
+	ImportUpdated   = "updated"
+	ImportUnchanged = "unchanged"
+	ImportFailed    = "failed"
+)
+
+// importresult, hasil import satu file
+type ImportResult struct {
+	File   string `json:"file"`
+	ID     string `json:"id,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	// warnings, bagian file yang ga bisa dipetain penuh tapi artikelnya tetep disimpen
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// importsource, sumber file markdown yang mau di-import
+// fn dipanggil sekali per file, jadi isi zip/folder ga perlu dimuat semua ke memori
+type ImportSource func(fn func(name string, r io.Reader) error) error
+
+// ismarkdown, cuma file .md / .markdown yang diproses, sisanya (gambar dll) dilewatin
+func isMarkdown(name string) bool {
+	ext := strings.ToLower(path.Ext(name))
+	return ext == ".md" || ext == ".markdown"
+}
+
+// zipsource, baca file markdown dari arsip zip
+func ZipSource(r io.ReaderAt, size int64) ImportSource {
+	return func(fn func(string, io.Reader) error) error {
+		zr, err := zip.NewReader(r, size)
+		if err != nil {
+			return err
+		}
+		for _, f := range zr.File {
+			if f.FileInfo().IsDir() || !isMarkdown(f.Name) {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return err
+			}
+			err = fn(path.Clean(f.Name), rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// dirsource, baca file markdown dari folder (rekursif)
+// nama file dibikin relatif ke folder biar id turunan tetep sama walau foldernya dipindah
+func DirSource(dir string) ImportSource {
+	return func(fn func(string, io.Reader) error) error {
+		return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !isMarkdown(p) {
+				return err
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return fn(filepath.ToSlash(rel), f)
+		})
+	}
+}
+
+// filesource, satu file markdown doang (misal upload tanpa zip)
+func FileSource(name string, r io.Reader) ImportSource {
+	return func(fn func(string, io.Reader) error) error {
+		return fn(name, r)
+	}
+}
+
+// validimportid, id dari front matter harus aman dipake di url
+var validImportID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// importid, id artikel hasil import
+// pake id dari front matter kalo ada, kalo ga ada diturunin dari owner + path file
+// jadi import ulang file yang sama selalu nemu artikel yang sama
+func importID(fm frontMatter, ownerID, name string) (string, error) {
+	if id := fm["id"]; id != "" {
+		if !validImportID.MatchString(id) {
+			return "", fmt.Errorf("id %q cuma boleh huruf, angka, - dan _", id)
+		}
+		return id, nil
+	}
+	sum := sha256.Sum256([]byte(ownerID + "\x00" + name))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// importtitle, judul dari front matter, heading pertama, atau nama file
+func importTitle(fm frontMatter, body, name string) string {
+	if t := fm["title"]; t != "" {
+		return t
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return strings.TrimSuffix(path.Base(name), path.Ext(name))
+}
+
+// parsemarkdownarticle, ubah satu file markdown jadi artikel
+func (s *ArticleService) parseMarkdownArticle(ownerID, name, data string) (models.Article, error) {
+	fm, body, err := splitFrontMatter(data)
+	if err != nil {
+		return models.Article{}, err
+	}
+	id, err := importID(fm, ownerID, name)
+	if err != nil {
+		return models.Article{}, err
+	}
+
+	a := models.Article{
+		ID:      id,
+		Title:   importTitle(fm, body, name),
+		Author:  fm.first("author", "authors"),
+		Content: sanitizeHTML(strings.Trim(body, "\n")),
+		OwnerID: ownerID,
+		Theme:   NormalizeTheme(fm["theme"]),
+	}
+
+	// tanggal yang ga ada dibiarin kosong, diisi pas disimpen (lihat importone)
+	if v := fm.first("created", "date", "created_at", "published"); v != "" {
+		if a.CreatedAt, err = parseFrontMatterTime(v); err != nil {
+			return models.Article{}, err
+		}
+	}
+	a.UpdatedAt = a.CreatedAt
+	if v := fm.first("updated", "lastmod", "updated_at", "modified"); v != "" {
+		if a.UpdatedAt, err = parseFrontMatterTime(v); err != nil {
+			return models.Article{}, err
+		}
+	}
+	if v := fm["views"]; v != "" {
+		if a.Views, err = strconv.Atoi(v); err != nil || a.Views < 0 {
+			return models.Article{}, fmt.Errorf("views %q bukan angka", v)
+		}
+	}
+	return a, nil
+}
+
+// conflictid, id pengganti kalo id dari file udah dipake artikel owner lain
+// diturunin dari owner + id asli, jadi import ulang file yang sama tetep nemu artikel yang sama,
+// dan importer ga bisa nebak-nebak id milik orang lain dari error-nya
+func conflictID(ownerID, id string) string {
+	sum := sha256.Sum256([]byte(ownerID + "\x00id\x00" + id))
+	return hex.EncodeToString(sum[:8])
+}
+
+// importone, simpen satu artikel hasil parse, balikin id yang akhirnya dipake
+// input dicek sama kayak create biar import ga bisa masukin artikel yang ditolak form
+// artikel yang udah ada di-update (views sama tanggal dibuat yang lama dipertahanin),
+// yang isinya sama dilewatin
+func (s *ArticleService) importOne(a models.Article) (string, string, error) {
+	if err := validateInput(a.Title, a.Author, a.Content); err != nil {
+		return "", "", err
+	}
+	a = WithMetadata()(a)
+	// pemiliknya dicek lewat ownerof, bukan get, biar artikel yang udah dihapus juga keitung
+	// (barisnya masih ada, jadi create bakal nabrak primary key)
+	owner, deleted, err := s.repo.OwnerOf(a.ID)
+	if err == nil && owner != a.OwnerID {
+		a.ID = conflictID(a.OwnerID, a.ID)
+		owner, deleted, err = s.repo.OwnerOf(a.ID)
+		if err == nil && owner != a.OwnerID {
+			return "", "", errors.New("id bentrok, ganti id di front matter")
+		}
+	}
+	if err == nil && deleted {
+		return "", "", fmt.Errorf("artikel %s udah dihapus, pulihin dulu atau ganti id-nya biar jadi artikel baru", a.ID)
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		if a.CreatedAt.IsZero() {
+			a.CreatedAt = s.clock()
+		}
+		if a.UpdatedAt.IsZero() {
+			a.UpdatedAt = a.CreatedAt
+		}
+		if err := s.repo.Create(a); err != nil {
+			return "", "", err
+		}
+		s.emit(EventArticleCreated)
+		return a.ID, ImportCreated, nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	existing, err := s.repo.Get(a.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	// tanggal dibandingin per detik karena file hasil export cuma nyimpen sampe detik
+	sameTime := a.UpdatedAt.IsZero() || existing.UpdatedAt.Truncate(time.Second).Equal(a.UpdatedAt.Truncate(time.Second))
+	// tema kosong di file artinya ga diatur, tema yang udah dipilih ga ikut kehapus
+	sameTheme := a.Theme == "" || a.Theme == existing.Theme
+	if existing.Title == a.Title && existing.Author == a.Author && existing.Content == a.Content && sameTime && sameTheme {
+		return a.ID, ImportUnchanged, nil
+	}
+	updated := existing
+	updated.Title = a.Title
+	updated.Author = a.Author
+	updated.Content = a.Content
+	if a.Theme != "" {
+		updated.Theme = a.Theme
+	}
+	updated.WordCount, updated.ReadingMinutes, updated.Excerpt = a.WordCount, a.ReadingMinutes, a.Excerpt
+	updated.UpdatedAt = a.UpdatedAt
+	if updated.UpdatedAt.IsZero() {
+		updated.UpdatedAt = s.clock()
+	}
+	if err := s.repo.Update(updated); err != nil {
+		return "", "", err
+	}
+	s.emit(EventArticleUpdated)
+	return a.ID, ImportUpdated, nil
+}
+
+// importarticle, simpen satu artikel dari importer lain (misal telegraph)
+// content masih teks biasa, id wajib diisi pemanggil biar import ulang tetep idempoten
+// id yang dibalikin bisa beda dari a.id kalo id-nya udah dipake owner lain (lihat conflictid)
+func (s *ArticleService) ImportArticle(a models.Article) (id, status string, err error) {
+	if a.ID == "" || a.OwnerID == "" {
+		return "", "", errors.New("artikel import butuh id dan owner")
+	}
+	a.Content = sanitizeHTML(a.Content)
+	return s.importOne(a)
+}
+
+// import, masukin semua file markdown dari src sebagai artikel milik owner
+// file yang gagal ga ngebatalin file lain, semuanya dilaporin di hasil
+// error cuma dibalikin kalo sumbernya sendiri ga bisa dibaca (misal zip rusak)
+func (s *ArticleService) Import(ownerID string, src ImportSource) ([]ImportResult, error) {
+	results := []ImportResult{}
+	err := src(func(name string, r io.Reader) error {
+		res := ImportResult{File: name}
+		fail := func(err error) error {
+			res.Status = ImportFailed
+			res.Error = err.Error()
+			results = append(results, res)
+			return nil
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r, maxImportFileSize+1))
+		if err != nil {
+			return fail(err)
+		}
+		if len(data) > maxImportFileSize {
+			return fail(fmt.Errorf("file lebih dari %d MB", maxImportFileSize>>20))
+		}
+		a, err := s.parseMarkdownArticle(ownerID, name, string(data))
+		if err != nil {
+			return fail(err)
+		}
+		res.ID, res.Title = a.ID, a.Title
+		if res.ID, res.Status, err = s.importOne(a); err != nil {
+			res.ID = a.ID
+			return fail(err)
+		}
+		results = append(results, res)
+		return nil
+	})
+	return results, err
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+)
+
+// importmarkdown, import satu file markdown dengan id di front matter, balikin hasilnya
+func importMarkdown(t *testing.T, svc *ArticleService, owner, id string) ImportResult {
+	t.Helper()
+	data := "---\ntitle: Judul\nid: " + id + "\n---\nisi artikel\n"
+	results, err := svc.Import(owner, FileSource("a.md", strings.NewReader(data)))
+	if err != nil || len(results) != 1 {
+		t.Fatalf("import: %v %v", results, err)
+	}
+	return results[0]
+}
+
+// artikel yang udah dihapus barisnya masih ada, import ulang ga boleh nabrak primary key
+// owner lain dapet id turunan, owner yang sama dapet pesan yang jelas
+func TestImportOverDeletedArticle(t *testing.T) {
+	svc := NewArticleService(repository.NewMemoryRepo(), NewRealClock(), NewRealIDGen())
+	if res := importMarkdown(t, svc, "owner", "post1"); res.Status != ImportCreated {
+		t.Fatalf("first import: %+v", res)
+	}
+	if err := svc.Delete("post1", "owner"); err != nil {
+		t.Fatal(err)
+	}
+
+	res := importMarkdown(t, svc, "owner", "post1")
+	if res.Status != ImportFailed || !strings.Contains(res.Error, "udah dihapus") {
+		t.Fatalf("same owner over deleted article: %+v", res)
+	}
+
+	res = importMarkdown(t, svc, "other", "post1")
+	if res.Status != ImportCreated || res.ID == "post1" || res.ID != conflictID("other", "post1") {
+		t.Fatalf("other owner over deleted article: %+v", res)
+	}
+	if again := importMarkdown(t, svc, "other", "post1"); again.Status != ImportUnchanged || again.ID != res.ID {
+		t.Fatalf("re-import by other owner: %+v", again)
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// yamlstring, quote string jadi scalar yaml double-quoted
+// escaping json itu subset yang valid buat yaml, jadi aman dipake
+func yamlString(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// contenttotext, balikin content tersimpan (newline jadi <br>) ke teks biasa
+// kebalikan dari sanitizehtml
+func contentToText(content string) string {
+	return strings.ReplaceAll(content, "<br>", "\n")
+}
+
+// tomarkdown, ubah artikel jadi file markdown dengan yaml front matter
+// pure function: ga ada efek samping, cuma bikin string
+func toMarkdown(a models.Article) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %s\n", yamlString(a.ID))
+	fmt.Fprintf(&b, "title: %s\n", yamlString(a.Title))
+	fmt.Fprintf(&b, "author: %s\n", yamlString(a.Author))
+	fmt.Fprintf(&b, "created: %s\n", a.CreatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "updated: %s\n", a.UpdatedAt.Format(time.RFC3339))
+	fmt.Fprintf(&b, "views: %d\n", a.Views)
+	if a.Theme != "" {
+		fmt.Fprintf(&b, "theme: %s\n", a.Theme)
+	}
+	b.WriteString("---\n\n")
+	b.WriteString(contentToText(a.Content))
+	b.WriteString("\n")
+	return b.String()
+}
+
+// slugify, bikin potongan nama file yang aman dari judul
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 60 {
+			break
+		}
+	}
+	slug := strings.Trim(b.String(), "-")
+	if slug == "" {
+		return "artikel"
+	}
+	return slug
+}
+
+// frontmatter, pasangan key-value dari header yaml file markdown
+type frontMatter map[string]string
+
+// splitfrontmatter, pisahin yaml front matter dari body markdown
+// file tanpa front matter tetep valid, hasilnya map kosong + seluruh isi jadi body
+func splitFrontMatter(data string) (frontMatter, string, error) {
+	data = strings.TrimPrefix(data, "\ufeff") // bom dari editor windows
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	fm := frontMatter{}
+	if !strings.HasPrefix(data, "---\n") {
+		return fm, data, nil
+	}
+	// newline pembuka ikut disimpen biar front matter kosong ("---\n---") juga ketemu
+	rest := data[len("---"):]
+	end := strings.Index(rest, "\n---")
+	if end < 0 {
+		return nil, "", errors.New("front matter ga ditutup dengan ---")
+	}
+	header, body := rest[:end+1], rest[end+len("\n---"):]
+	// sisa baris penutup (misal "---" atau "...") dibuang
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+
+	for n, line := range strings.Split(header, "\n") {
+		trimmed := strings.TrimSpace(line)
+		// baris kosong, komentar, sama isi list/map bersarang (misal tags) dilewatin
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || line[0] == ' ' || line[0] == '\t' || strings.HasPrefix(trimmed, "- ") {
+			continue
+		}
+		key, raw, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, "", fmt.Errorf("front matter baris %d: ga ada ':'", n+1)
+		}
+		value, err := yamlScalar(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, "", fmt.Errorf("front matter baris %d: %w", n+1, err)
+		}
+		fm[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return fm, body, nil
+}
+
+// yamlscalar, baca satu nilai yaml sederhana: plain, "double" atau 'single' quoted
+func yamlScalar(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"`):
+		var s string
+		if err := json.Unmarshal([]byte(raw), &s); err != nil {
+			return "", fmt.Errorf("string %s ga valid", raw)
+		}
+		return s, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("string %s ga ditutup", raw)
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	}
+	// komentar di akhir baris ikut dibuang
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	return raw, nil
+}
+
+// first, ambil nilai pertama yang ga kosong dari beberapa key alternatif
+// static site generator beda-beda namain field, misal date vs created
+func (fm frontMatter) first(keys ...string) string {
+	for _, k := range keys {
+		if v := fm[k]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// parsefrontmattertime, format tanggal yang biasa dipake static site generator
+func parseFrontMatterTime(s string) (time.Time, error) {
+	layouts := []string{time.RFC3339Nano, "2006-01-02 15:04:05Z07:00", "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("tanggal %q ga dikenal", s)
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+const (
+	// wordsperminute, kecepatan baca rata-rata orang dewasa
+	// bahasa indonesia sama inggris kurang lebih sama (kata indonesia lebih panjang tapi lebih jarang)
+	wordsPerMinute = 200
+	// excerptlength, panjang maksimal cuplikan dalam huruf
+	excerptLength = 160
+)
+
+var (
+	// htmltag, tag html apa aja yang mungkin keikut di content
+	htmlTag = regexp.MustCompile(`<[^>]*>`)
+	// mdimage sama mdlink, ambil teksnya aja, url dibuang
+	mdImage = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	mdLink  = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	// mdprefix, penanda blok di awal baris: heading, quote, list
+	mdPrefix = regexp.MustCompile(`(?m)^\s*(#{1,6}|>|[-*+]|\d+\.)\s+`)
+	// mdsymbol, penanda inline dan garis: **, ~~, `, ```, ---
+	mdSymbol = regexp.MustCompile("(\\*\\*|~~|```|`|(?m:^-{3,}$))")
+)
+
+// plaintext, buang semua markup dari content, sisain teks yang kebaca manusia
+// pure function: urutannya penting, gambar harus dibuang sebelum link
+func plainText(content string) string {
+	text := strings.ReplaceAll(content, "<br>", "\n")
+	text = htmlTag.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = mdImage.ReplaceAllString(text, "$1")
+	text = mdLink.ReplaceAllString(text, "$1")
+	text = mdPrefix.ReplaceAllString(text, "")
+	text = mdSymbol.ReplaceAllString(text, "")
+	return text
+}
+
+// countwords, hitung kata di teks polos
+// tanda hubung sama apostrof di tengah kata ikut bagian kata,
+// jadi "anak-anak", "mem-posting" sama "don't" dihitung satu
+func countWords(text string) int {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '\'' || r == '’')
+	})
+	count := 0
+	for _, f := range fields {
+		// potongan yang isinya cuma tanda baca (misal "--") bukan kata
+		if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// readingminutes, perkiraan menit baca, dibuletin ke atas, minimal 1 kalo ada isinya
+func readingMinutes(words int) int {
+	if words == 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// makeexcerpt, cuplikan awal teks, dipotong di batas kata biar ga kepotong di tengah
+func makeExcerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= excerptLength {
+		return text
+	}
+	cut := string([]rune(text)[:excerptLength])
+	if i := strings.LastIndexByte(cut, ' '); i > excerptLength/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:-") + "…"
+}
+
+// withmetadata, transform yang ngisi jumlah kata, menit baca, sama cuplikan dari content
+// dipanggil tiap create/update biar metadata selalu sinkron sama isinya
+func WithMetadata() ArticleTransform {
+	return func(a models.Article) models.Article {
+		text := plainText(a.Content)
+		a.WordCount = countWords(text)
+		a.ReadingMinutes = readingMinutes(a.WordCount)
+		a.Excerpt = makeExcerpt(text)
+		return a
+	}
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+	"github.com/fhmptrdnd/private-blog/internal/repository"
+)
+
+// defaultstatsdays, rentang grafik tayangan bawaan (hari ini ikut dihitung)
+const DefaultStatsDays = 30
+
+// batas daftar teratas di halaman statistik sama dashboard
+const (
+	maxTopReferrers = 10
+	maxTopArticles  = 5
+)
+
+// dayviews, jumlah tayangan dalam satu hari (utc)
+type DayViews struct {
+	Day   time.Time
+	Views int
+}
+
+// articlestats, statistik satu artikel buat pemiliknya
+type ArticleStats struct {
+	Article   models.Article
+	Days      []DayViews // urut dari yang paling lama, hari tanpa kunjungan tetep ada (0)
+	Recent    int        // total tayangan dalam rentang days
+	Best      DayViews   // hari paling rame dalam rentang, kosong kalo belum ada kunjungan
+	Referrers []repository.ReferrerViews
+}
+
+// articlesummary, satu baris artikel di dashboard
+type ArticleSummary struct {
+	Article models.Article
+	Recent  int
+}
+
+// dashboard, ringkasan semua artikel milik satu user
+type Dashboard struct {
+	Articles  int
+	Views     int // total tayangan sepanjang masa
+	Recent    int // total tayangan dalam rentang days
+	Words     int
+	Days      []DayViews
+	Summaries []ArticleSummary // urutannya sama kayak listmyarticles
+	Top       []ArticleSummary // paling rame dalam rentang days
+}
+
+// statsrange, hari pertama rentang statistik, days hari ke belakang termasuk hari ini
+func statsRange(now time.Time, days int) time.Time {
+	today := now.UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, -(days - 1))
+}
+
+// dayseries, ubah baris per artikel per hari jadi deret harian yang lengkap
+// angka beberapa artikel di hari yang sama dijumlah
+func daySeries(since time.Time, days int, rows []repository.DailyViews) []DayViews {
+	series := make([]DayViews, days)
+	for i := range series {
+		series[i].Day = since.AddDate(0, 0, i)
+	}
+	for _, r := range rows {
+		i := int(r.Day.Sub(since) / (24 * time.Hour))
+		if i >= 0 && i < days {
+			series[i].Views += r.Views
+		}
+	}
+	return series
+}
+
+// articlestats, statistik artikel milik ownerid selama days hari terakhir
+// artikel punya orang lain dianggap ga ada, sama kayak update/delete
+func (s *ArticleService) ArticleStats(id, ownerID string, days int) (ArticleStats, error) {
+	if days <= 0 {
+		days = DefaultStatsDays
+	}
+	a, err := s.repo.Get(id)
+	if err != nil {
+		return ArticleStats{}, err
+	}
+	if a.OwnerID != ownerID {
+		return ArticleStats{}, repository.ErrNotFound
+	}
+
+	since := statsRange(s.clock(), days)
+	rows, err := s.repo.DailyViews(ownerID, id, since)
+	if err != nil {
+		return ArticleStats{}, err
+	}
+	refs, err := s.repo.TopReferrers(id, maxTopReferrers)
+	if err != nil {
+		return ArticleStats{}, err
+	}
+
+	st := ArticleStats{Article: a, Days: daySeries(since, days, rows), Referrers: refs}
+	for _, d := range st.Days {
+		st.Recent += d.Views
+		if d.Views > st.Best.Views {
+			st.Best = d
+		}
+	}
+	return st, nil
+}
+
+// dashboard, ringkasan semua artikel milik ownerid selama days hari terakhir
+// agregasinya pake parallelreduce: reducer ngitung satu artikel, combiner gabungin dua potongan
+func (s *ArticleService) Dashboard(ownerID string, days int) (Dashboard, error) {
+	if days <= 0 {
+		days = DefaultStatsDays
+	}
+	articles, err := s.repo.ListByOwner(ownerID)
+	if err != nil {
+		return Dashboard{}, err
+	}
+	since := statsRange(s.clock(), days)
+	rows, err := s.repo.DailyViews(ownerID, "", since)
+	if err != nil {
+		return Dashboard{}, err
+	}
+
+	// recent cuma dibaca di dalam reducer, jadi aman dipake barengan sama goroutine parallelreduce
+	recent := map[string]int{}
+	for _, r := range rows {
+		recent[r.ArticleID] += r.Views
+	}
+
+	// initial-nya dipake dua potongan sekaligus, jadi summaries-nya harus kosong
+	// biar append di tiap potongan bikin array sendiri
+	d := ParallelReduce(articles, Dashboard{},
+		func(d Dashboard, a models.Article) Dashboard {
+			sum := ArticleSummary{Article: a, Recent: recent[a.ID]}
+			d.Articles++
+			d.Views += a.Views
+			d.Recent += sum.Recent
+			d.Words += a.WordCount
+			d.Summaries = append(d.Summaries, sum)
+			return d
+		},
+		func(left, right Dashboard) Dashboard {
+			return Dashboard{
+				Articles:  left.Articles + right.Articles,
+				Views:     left.Views + right.Views,
+				Recent:    left.Recent + right.Recent,
+				Words:     left.Words + right.Words,
+				Summaries: append(left.Summaries, right.Summaries...),
+			}
+		},
+	)
+	d.Days = daySeries(since, days, rows)
+
+	// top, copy dulu biar urutan summaries ga ikut berubah
+	for _, sum := range d.Summaries {
+		if sum.Recent > 0 {
+			d.Top = append(d.Top, sum)
+		}
+	}
+	sort.SliceStable(d.Top, func(i, j int) bool {
+		return d.Top[i].Recent > d.Top[j].Recent
+	})
+	if len(d.Top) > maxTopArticles {
+		d.Top = d.Top[:maxTopArticles]
+	}
+	return d, nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// themes, tema baca yang bisa dipilih penulis atau pembaca
+var Themes = []string{"light", "dark", "sepia"}
+
+// normalizetheme, rapiin nama tema, yang ga dikenal jadi kosong (ikut pilihan pembaca)
+func NormalizeTheme(theme string) string {
+	theme = strings.ToLower(strings.TrimSpace(theme))
+	if slices.Contains(Themes, theme) {
+		return theme
+	}
+	return ""
+}
+
+// withtheme, transform yang ngeset saran tema artikel
+func WithTheme(theme string) ArticleTransform {
+	return func(a models.Article) models.Article {
+		updated := a
+		updated.Theme = NormalizeTheme(theme)
+		return updated
+	}
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// batas input artikel, dicek di create, update, sama preview
+const (
+	MaxTitleLength   = 256       // huruf
+	MaxAuthorLength  = 128       // huruf
+	MaxContentLength = 256 << 10 // byte
+)
+
+// validationerror, input artikel yang ditolak, field-nya ikut biar bisa ditampilin di form
+// key sama args buat diterjemahin lewat i18n.t, message buat log sama cli
+type ValidationError struct {
+	Field   string
+	Message string
+	Key     string
+	Args    []any
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// validateinput, cek judul, penulis, sama isi sebelum diproses
+// pure function: cuma liat input, balikin error pertama yang ketemu
+func validateInput(title, author, content string) error {
+	switch {
+	case strings.TrimSpace(title) == "":
+		return &ValidationError{"title", "judul wajib diisi", "validation.title_required", nil}
+	case utf8.RuneCountInString(title) > MaxTitleLength:
+		return &ValidationError{"title", "judul kepanjangan", "validation.title_too_long", []any{MaxTitleLength}}
+	case utf8.RuneCountInString(author) > MaxAuthorLength:
+		return &ValidationError{"author", "nama penulis kepanjangan", "validation.author_too_long", []any{MaxAuthorLength}}
+	case strings.TrimSpace(content) == "":
+		return &ValidationError{"content", "isi artikel wajib diisi", "validation.content_required", nil}
+	case len(content) > MaxContentLength:
+		return &ValidationError{"content", "isi artikel kegedean", "validation.content_too_large", []any{MaxContentLength >> 10}}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultviewwindow, pengunjung yang sama baru dihitung lagi kalo udah setengah jam ga buka artikelnya
+const DefaultViewWindow = 30 * time.Minute
+
+// maxtrackedvisits, batas pasangan artikel+pengunjung yang diinget biar memori ga bengkak
+const maxTrackedVisits = 100000
+
+// seenfunc, true kalo kunjungan ini dihitung (pengunjungnya belum keliatan dalam window)
+type SeenFunc func(articleID, visitor string) bool
+
+// newviewdedup, bikin seenfunc dengan window geser: tiap kunjungan ngegeser batasnya lagi,
+// jadi refresh berkali-kali cuma kehitung sekali selama jaraknya kurang dari window
+// state-nya di closure, dijaga satu mutex kayak rate limiter
+func NewViewDedup(window time.Duration, clock ClockFunc) SeenFunc {
+	if window <= 0 {
+		return func(string, string) bool { return true }
+	}
+	var mu sync.Mutex
+	last := map[string]time.Time{}
+	return func(articleID, visitor string) bool {
+		now := clock()
+		key := articleID + "\x00" + visitor
+
+		mu.Lock()
+		defer mu.Unlock()
+		if len(last) >= maxTrackedVisits {
+			for k, t := range last {
+				if now.Sub(t) >= window {
+					delete(last, k)
+				}
+			}
+			// masih penuh juga, mulai dari kosong. mending ada yang kehitung dobel daripada memori habis
+			if len(last) >= maxTrackedVisits {
+				last = map[string]time.Time{}
+			}
+		}
+		prev, ok := last[key]
+		last[key] = now
+		return !ok || now.Sub(prev) >= window
+	}
+}
+
+// withviewwindow, balikin copy service yang pake window dedup views sendiri (0 = tiap kunjungan dihitung)
+func (s *ArticleService) WithViewWindow(window time.Duration) *ArticleService {
+	copied := *s
+	copied.seen = NewViewDedup(window, s.clock)
+	return &copied
+}
+
+// recordview, catet satu kunjungan pembaca
+// views cuma nambah kalo visitor belum keliatan di artikel ini dalam window, counted ngasih tau hasilnya
+// yang nyaring bot, prefetch, sama pemilik artikel itu handler, service cuma tau id pengunjungnya
+// referrer itu domain asal pembaca (kosong = langsung), ikut dicatet di statistik harian
+func (s *ArticleService) RecordView(id, visitor, referrer string) (counted bool, err error) {
+	if !s.seen(id, visitor) {
+		return false, nil
+	}
+	if err := s.IncrementViews(id); err != nil {
+		return false, err
+	}
+	// views-nya udah kehitung, statistik yang gagal ga ngebatalin itu
+	if err := s.repo.RecordViewStats(id, s.clock(), referrer, 1); err != nil {
+		return true, err
+	}
+	return true, nil
+}
@@ -0,0 +1,215 @@
+package telegraph
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// baseurl, dipake buat ngelengkapin src relatif kayak /file/abc.jpg
+const baseURL = "https://telegra.ph"
+
+// blocktags, tag yang jadi paragraf sendiri, sisanya dianggap inline
+var blockTags = map[string]bool{
+	"p": true, "h3": true, "h4": true, "blockquote": true, "aside": true, "pre": true,
+	"figure": true, "figcaption": true, "hr": true, "ul": true, "ol": true, "li": true,
+	"img": true, "iframe": true, "video": true,
+}
+
+// tocontent, ubah node tree telegraph jadi teks artikel ala markdown
+// tag yang ga bisa direpresentasiin ga dibuang diem-diem, tapi dilaporin di warnings
+func ToContent(nodes []Node) (content string, warnings []string) {
+	counts := map[string]int{}
+	warn := func(msg string) { counts[msg]++ }
+
+	var block func(n Node) string
+	var inline func(nodes []Node) string
+
+	// inline, gabungin node inline jadi satu baris teks
+	inline = func(nodes []Node) string {
+		var b strings.Builder
+		for _, n := range nodes {
+			if n.IsText() {
+				b.WriteString(n.Text)
+				continue
+			}
+			text := inline(n.Children)
+			switch n.Tag {
+			case "a":
+				href := absURL(n.Attrs["href"])
+				if href == "" || href == text {
+					b.WriteString(text)
+				} else {
+					fmt.Fprintf(&b, "[%s](%s)", text, href)
+				}
+			case "b", "strong":
+				b.WriteString("**" + text + "**")
+			case "i", "em":
+				b.WriteString("*" + text + "*")
+			case "s":
+				b.WriteString("~~" + text + "~~")
+			case "code":
+				b.WriteString("`" + text + "`")
+			case "u":
+				// markdown ga punya garis bawah, teksnya aja yang diambil tapi tetep dilaporin
+				warn("garis bawah <u> ga didukung markdown, cuma teksnya yang diambil")
+				b.WriteString(text)
+			case "br":
+				b.WriteString("\n")
+			default:
+				if blockTags[n.Tag] {
+					b.WriteString(block(n))
+				} else {
+					warn(fmt.Sprintf("tag <%s> ga didukung, cuma teksnya yang diambil", n.Tag))
+					b.WriteString(text)
+				}
+			}
+		}
+		return b.String()
+	}
+
+	// block, satu node level paragraf
+	block = func(n Node) string {
+		switch n.Tag {
+		case "p", "figcaption":
+			return inline(n.Children)
+		case "h3":
+			return "### " + inline(n.Children)
+		case "h4":
+			return "#### " + inline(n.Children)
+		case "blockquote", "aside":
+			return prefixLines(inline(n.Children), "> ")
+		case "pre":
+			return "```\n" + strings.TrimRight(plainText(n.Children), "\n") + "\n```"
+		case "hr":
+			return "---"
+		case "img":
+			return "![](" + absURL(n.Attrs["src"]) + ")"
+		case "iframe", "video":
+			warn(fmt.Sprintf("embed <%s> ga didukung, diganti link", n.Tag))
+			return "[" + n.Tag + "](" + embedURL(n.Attrs["src"]) + ")"
+		case "figure":
+			return figure(n, inline, warn)
+		case "ul", "ol":
+			var items []string
+			for i, li := range n.Children {
+				marker := "- "
+				if n.Tag == "ol" {
+					marker = fmt.Sprintf("%d. ", i+1)
+				}
+				if li.Tag == "li" {
+					items = append(items, marker+inline(li.Children))
+				} else {
+					items = append(items, marker+inline([]Node{li}))
+				}
+			}
+			return strings.Join(items, "\n")
+		case "li":
+			return "- " + inline(n.Children)
+		}
+		return inline([]Node{n})
+	}
+
+	// node inline yang berurutan di level atas digabung jadi satu paragraf
+	var paragraphs []string
+	var pending []Node
+	flush := func() {
+		if text := strings.TrimSpace(inline(pending)); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+		pending = nil
+	}
+	for _, n := range nodes {
+		if n.IsText() || !blockTags[n.Tag] {
+			pending = append(pending, n)
+			continue
+		}
+		flush()
+		if text := block(n); strings.TrimSpace(text) != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	flush()
+
+	for msg, c := range counts {
+		if c > 1 {
+			msg = fmt.Sprintf("%s (%dx)", msg, c)
+		}
+		warnings = append(warnings, msg)
+	}
+	sort.Strings(warnings)
+	return strings.Join(paragraphs, "\n\n"), warnings
+}
+
+// figure, gambar/embed plus caption-nya
+// caption jadi alt text gambar biar tetep nempel sama gambarnya
+func figure(n Node, inline func([]Node) string, warn func(string)) string {
+	var media *Node
+	var caption string
+	for i, c := range n.Children {
+		switch c.Tag {
+		case "img", "iframe", "video":
+			media = &n.Children[i]
+		case "figcaption":
+			caption = strings.TrimSpace(inline(c.Children))
+		}
+	}
+	if media == nil {
+		return caption
+	}
+	if media.Tag == "img" {
+		return "![" + caption + "](" + absURL(media.Attrs["src"]) + ")"
+	}
+	warn(fmt.Sprintf("embed <%s> ga didukung, diganti link", media.Tag))
+	label := caption
+	if label == "" {
+		label = media.Tag
+	}
+	return "[" + label + "](" + embedURL(media.Attrs["src"]) + ")"
+}
+
+// plaintext, semua teks di dalam node tanpa format (buat isi pre)
+func plainText(nodes []Node) string {
+	var b strings.Builder
+	for _, n := range nodes {
+		switch {
+		case n.IsText():
+			b.WriteString(n.Text)
+		case n.Tag == "br":
+			b.WriteString("\n")
+		default:
+			b.WriteString(plainText(n.Children))
+		}
+	}
+	return b.String()
+}
+
+// prefixlines, tambahin prefix di tiap baris (buat blockquote)
+func prefixLines(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = prefix + l
+	}
+	return strings.Join(lines, "\n")
+}
+
+// absurl, lengkapin path relatif telegraph jadi url penuh
+func absURL(src string) string {
+	if strings.HasPrefix(src, "/") && !strings.HasPrefix(src, "//") {
+		return baseURL + src
+	}
+	return src
+}
+
+// embedurl, embed telegraph bentuknya /embed/youtube?url=..., ambil url aslinya
+func embedURL(src string) string {
+	if strings.HasPrefix(src, "/embed/") {
+		if u, err := url.Parse(src); err == nil {
+			if orig := u.Query().Get("url"); orig != "" {
+				return orig
+			}
+		}
+	}
+	return absURL(src)
+}
@@ -0,0 +1,135 @@
+package telegraph
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// imageline, baris gambar hasil tocontent: ![caption](src)
+	imageLine = regexp.MustCompile(`^!\[([^\]]*)\]\((\S+)\)$`)
+	// orderedline, item list bernomor: "1. teks"
+	orderedLine = regexp.MustCompile(`^\d+\. `)
+	// inlinemarkup, link, tebel, sama kode inline
+	inlineMarkup = regexp.MustCompile("\\[([^\\]]+)\\]\\(([^)\\s]+)\\)|\\*\\*([^*]+)\\*\\*|`([^`]+)`")
+)
+
+// fromcontent, kebalikan tocontent: teks artikel jadi node tree telegraph
+// cuma ngenalin pola yang dihasilin tocontent, sisanya jadi paragraf biasa
+func FromContent(text string) []Node {
+	lines := strings.Split(strings.ReplaceAll(text, "\r", ""), "\n")
+	nodes := []Node{}
+	var para []string
+
+	// withbreaks, gabung beberapa baris jadi inline node dengan <br> di antaranya
+	withBreaks := func(lines []string) []Node {
+		var out []Node
+		for i, l := range lines {
+			if i > 0 {
+				out = append(out, Node{Tag: "br"})
+			}
+			out = append(out, inlineNodes(l)...)
+		}
+		return out
+	}
+	flush := func() {
+		if len(para) > 0 {
+			nodes = append(nodes, Node{Tag: "p", Children: withBreaks(para)})
+			para = nil
+		}
+	}
+	// collect, ambil baris berurutan yang cocok sama pola, dipake buat quote dan list
+	collect := func(i int, match func(string) bool) ([]string, int) {
+		var out []string
+		for ; i < len(lines) && match(lines[i]); i++ {
+			out = append(out, lines[i])
+		}
+		return out, i - 1
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "```"):
+			flush()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(lines[i], "```"); i++ {
+				code = append(code, lines[i])
+			}
+			nodes = append(nodes, Node{Tag: "pre", Children: []Node{{Text: strings.Join(code, "\n")}}})
+		case strings.TrimSpace(line) == "":
+			flush()
+		case strings.HasPrefix(line, "#### "):
+			flush()
+			nodes = append(nodes, Node{Tag: "h4", Children: inlineNodes(line[5:])})
+		case strings.HasPrefix(line, "### "):
+			flush()
+			nodes = append(nodes, Node{Tag: "h3", Children: inlineNodes(line[4:])})
+		case line == "---":
+			flush()
+			nodes = append(nodes, Node{Tag: "hr"})
+		case strings.HasPrefix(line, ">"):
+			flush()
+			var quote []string
+			quote, i = collect(i, func(l string) bool { return strings.HasPrefix(l, ">") })
+			for j, q := range quote {
+				quote[j] = strings.TrimPrefix(strings.TrimPrefix(q, ">"), " ")
+			}
+			nodes = append(nodes, Node{Tag: "blockquote", Children: withBreaks(quote)})
+		case imageLine.MatchString(line):
+			flush()
+			m := imageLine.FindStringSubmatch(line)
+			fig := Node{Tag: "figure", Children: []Node{{Tag: "img", Attrs: map[string]string{"src": m[2]}}}}
+			if m[1] != "" {
+				fig.Children = append(fig.Children, Node{Tag: "figcaption", Children: []Node{{Text: m[1]}}})
+			}
+			nodes = append(nodes, fig)
+		case strings.HasPrefix(line, "- "), orderedLine.MatchString(line):
+			flush()
+			tag, isItem := "ul", func(l string) bool { return strings.HasPrefix(l, "- ") }
+			if !strings.HasPrefix(line, "- ") {
+				tag, isItem = "ol", orderedLine.MatchString
+			}
+			var items []string
+			items, i = collect(i, isItem)
+			list := Node{Tag: tag}
+			for _, it := range items {
+				it = strings.TrimPrefix(it, "- ")
+				if tag == "ol" {
+					it = orderedLine.ReplaceAllString(it, "")
+				}
+				list.Children = append(list.Children, Node{Tag: "li", Children: inlineNodes(it)})
+			}
+			nodes = append(nodes, list)
+		default:
+			para = append(para, line)
+		}
+	}
+	flush()
+	return nodes
+}
+
+// inlinenodes, pecah satu baris jadi teks, link, tebel, sama kode
+func inlineNodes(s string) []Node {
+	var out []Node
+	last := 0
+	for _, m := range inlineMarkup.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] > last {
+			out = append(out, Node{Text: s[last:m[0]]})
+		}
+		switch {
+		case m[2] >= 0:
+			out = append(out, Node{Tag: "a", Attrs: map[string]string{"href": s[m[4]:m[5]]},
+				Children: []Node{{Text: s[m[2]:m[3]]}}})
+		case m[6] >= 0:
+			out = append(out, Node{Tag: "strong", Children: []Node{{Text: s[m[6]:m[7]]}}})
+		default:
+			out = append(out, Node{Tag: "code", Children: []Node{{Text: s[m[8]:m[9]]}}})
+		}
+		last = m[1]
+	}
+	if last < len(s) {
+		out = append(out, Node{Text: s[last:]})
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+// package telegraph, format data telegra.ph (page + node tree) dan konversinya ke artikel
+package telegraph
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/fhmptrdnd/private-blog/internal/models"
+)
+
+// node, satu simpul konten telegraph
+// di json bentuknya bisa string (teks) atau object nodeelement, jadi dua-duanya ditampung di sini
+type Node struct {
+	Text     string            // diisi kalo node-nya teks
+	Tag      string            // diisi kalo node-nya element
+	Attrs    map[string]string // cuma href sama src yang dipake telegraph
+	Children []Node
+}
+
+// nodeelement, bentuk json element telegraph
+type nodeElement struct {
+	Tag      string            `json:"tag"`
+	Attrs    map[string]string `json:"attrs,omitempty"`
+	Children []Node            `json:"children,omitempty"`
+}
+
+// istext, node teks atau bukan
+func (n Node) IsText() bool {
+	return n.Tag == ""
+}
+
+// unmarshaljson, string jadi node teks, object jadi element
+func (n *Node) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		*n = Node{}
+		return json.Unmarshal(data, &n.Text)
+	}
+	var el nodeElement
+	if err := json.Unmarshal(data, &el); err != nil {
+		return err
+	}
+	if el.Tag == "" {
+		return errors.New("node element tanpa tag")
+	}
+	*n = Node{Tag: el.Tag, Attrs: el.Attrs, Children: el.Children}
+	return nil
+}
+
+// marshaljson, kebalikan unmarshaljson
+func (n Node) MarshalJSON() ([]byte, error) {
+	if n.IsText() {
+		return json.Marshal(n.Text)
+	}
+	return json.Marshal(nodeElement{Tag: n.Tag, Attrs: n.Attrs, Children: n.Children})
+}
+
+// page, object page dari api telegraph
+type Page struct {
+	Path        string `json:"path"`
+	URL         string `json:"url"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	AuthorName  string `json:"author_name,omitempty"`
+	AuthorURL   string `json:"author_url,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	Content     []Node `json:"content,omitempty"`
+	Views       int    `json:"views"`
+	CanEdit     bool   `json:"can_edit,omitempty"`
+}
+
+// parsepage, baca page dari json
+// terima page mentah atau respon api lengkap {"ok": true, "result": {...}}
+func ParsePage(r io.Reader) (Page, error) {
+	var raw struct {
+		Page
+		OK     *bool           `json:"ok"`
+		Error  string          `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return Page{}, err
+	}
+	p := raw.Page
+	if raw.OK != nil {
+		if !*raw.OK {
+			return Page{}, fmt.Errorf("respon telegraph gagal: %s", raw.Error)
+		}
+		if err := json.Unmarshal(raw.Result, &p); err != nil {
+			return Page{}, err
+		}
+	}
+	if p.Title == "" {
+		return Page{}, errors.New("page ga punya title")
+	}
+	if p.Content == nil {
+		return Page{}, errors.New("page ga punya content (ambil pake getPage?return_content=true)")
+	}
+	return p, nil
+}
+
+// article, ubah page jadi artikel milik owner (content masih teks biasa, belum di-sanitize)
+// id diturunin dari owner + path page, jadi import ulang page yang sama ga bikin dobel
+// tanggal ga diisi karena page telegraph emang ga nyimpen tanggal
+func (p Page) Article(ownerID string) (models.Article, []string) {
+	key := p.Path
+	if key == "" {
+		key = p.Title
+	}
+	sum := sha256.Sum256([]byte("telegraph\x00" + ownerID + "\x00" + key))
+	content, warnings := ToContent(p.Content)
+	return models.Article{
+		ID:      hex.EncodeToString(sum[:8]),
+		Title:   p.Title,
+		Author:  p.AuthorName,
+		Content: content,
+		Views:   p.Views,
+		OwnerID: ownerID,
+	}, warnings
+}
+
+// account, object account dari api telegraph
+type Account struct {
+	ShortName   string `json:"short_name"`
+	AuthorName  string `json:"author_name"`
+	AuthorURL   string `json:"author_url"`
+	AccessToken string `json:"access_token,omitempty"`
+	AuthURL     string `json:"auth_url,omitempty"`
+	PageCount   int    `json:"page_count,omitempty"`
+}
+
+// pagelist, hasil getpagelist
+type PageList struct {
+	TotalCount int    `json:"total_count"`
+	Pages      []Page `json:"pages"`
+}